@@ -0,0 +1,52 @@
+package v1alpha1
+
+// Condition types reporting readiness of individual operands
+const (
+	ConditionTektonTasksReady     = "TektonTasksReady"
+	ConditionTektonPipelinesReady = "TektonPipelinesReady"
+)
+
+// ConditionUpgrading is true, while resources deployed by a previous version of the operator are being upgraded
+const ConditionUpgrading = "Upgrading"
+
+// Reasons used in conditions of the TektonTasks CR
+const (
+	// ReasonAsExpected is used when the condition is in its expected state
+	ReasonAsExpected = "AsExpected"
+	// ReasonReconciling is used while resources are being reconciled
+	ReasonReconciling = "Reconciling"
+	// ReasonResourcesNotAvailable is used when some managed resources are not available
+	ReasonResourcesNotAvailable = "ResourcesNotAvailable"
+	// ReasonResourcesProgressing is used when some managed resources are progressing
+	ReasonResourcesProgressing = "ResourcesProgressing"
+	// ReasonResourcesDegraded is used when some managed resources are degraded
+	ReasonResourcesDegraded = "ResourcesDegraded"
+	// ReasonResourcesNotReady is used when some resources of an operand are not ready
+	ReasonResourcesNotReady = "ResourcesNotReady"
+	// ReasonResourceConflict is used when existing resources are not owned by the operator and were not adopted
+	ReasonResourceConflict = "ResourceConflict"
+	// ReasonDependencyFailed is used when resources were skipped, because their prerequisites failed
+	ReasonDependencyFailed = "DependencyFailed"
+	// ReasonBundleLoadFailed is used when the task or pipeline bundle could not be loaded
+	ReasonBundleLoadFailed = "BundleLoadFailed"
+	// ReasonCRDMissing is used when CRDs required by the operator are not installed
+	ReasonCRDMissing = "CRDMissing"
+	// ReasonMultipleCRs is used when more than one TektonTasks CR exists
+	ReasonMultipleCRs = "MultipleCRs"
+	// ReasonPaused is used when reconciliation is paused by the paused annotation
+	ReasonPaused = "Paused"
+	// ReasonDeploymentDisabled is used when deployment of resources is disabled by a feature gate
+	ReasonDeploymentDisabled = "DeploymentDisabled"
+	// ReasonDeleting is used while resources are being deleted
+	ReasonDeleting = "Deleting"
+	// ReasonReconcileFailed is used when reconciliation failed with an error
+	ReasonReconcileFailed = "ReconcileFailed"
+	// ReasonUpgrading is used while resources are upgraded to the operator version
+	ReasonUpgrading = "Upgrading"
+	// ReasonMigrationsPending is used when upgrade migrations have not completed
+	ReasonMigrationsPending = "MigrationsPending"
+	// ReasonUpdateDeferred is used when updates of resources are deferred, because runs reference them
+	ReasonUpdateDeferred = "UpdateDeferred"
+	// ReasonOutsideUpdateWindow is used when updates of resources are deferred until the next update window
+	ReasonOutsideUpdateWindow = "OutsideUpdateWindow"
+)
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 )
 
@@ -29,8 +30,93 @@ const (
 type TektonTasksSpec struct {
 	Pipelines    Pipelines    `json:"pipelines,omitempty"`
 	FeatureGates FeatureGates `json:"featureGates,omitempty"`
+
+	// AdoptionPolicy defines how existing resources, that are not owned by the operator, are handled.
+	// Defaults to Adopt.
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// Platform overrides detection of platform capabilities, that select variants of deployed bundles.
+	// Defaults to Auto.
+	// +optional
+	Platform Platform `json:"platform,omitempty"`
+
+	// TopologyMode overrides the infrastructure topology read from the OpenShift Infrastructure resource.
+	// Example pipelines need less resources, when it is SingleReplica.
+	// +optional
+	TopologyMode TopologyMode `json:"topologyMode,omitempty"`
+
+	// UpdateStrategy defines how changed ClusterTasks and Pipelines are updated.
+	// +optional
+	UpdateStrategy UpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// UpdateWindows restrict updates of existing tasks, pipelines and their resources to maintenance windows.
+	// Outside the windows, missing resources are created, but existing resources are neither updated,
+	// nor reverted when they were changed. When empty, resources are updated at any time.
+	// +optional
+	UpdateWindows []UpdateWindow `json:"updateWindows,omitempty"`
 }
 
+// UpdateWindow defines a recurring maintenance window
+type UpdateWindow struct {
+	// Schedule is a cron expression with five fields, that defines when the window opens, e.g. "0 2 * * 6".
+	// It is evaluated in UTC, unless it is prefixed with CRON_TZ=<time zone>.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open, e.g. "4h".
+	Duration metav1.Duration `json:"duration"`
+}
+
+// UpdateStrategy defines when the operator updates ClusterTasks and Pipelines, that are in use
+type UpdateStrategy struct {
+	// WaitForRuns defers spec updates of ClusterTasks and Pipelines, while running TaskRuns
+	// or PipelineRuns reference them.
+	// +optional
+	WaitForRuns bool `json:"waitForRuns,omitempty"`
+
+	// MaxWait limits how long an update is deferred. When it elapses, the resource is updated,
+	// even if runs still reference it. Defaults to 1h.
+	// +optional
+	MaxWait *metav1.Duration `json:"maxWait,omitempty"`
+}
+
+// TopologyMode defines the topology of nodes running workloads
+// +kubebuilder:validation:Enum=HighlyAvailable;SingleReplica
+type TopologyMode string
+
+const (
+	// TopologyModeHighlyAvailable is used on clusters with multiple worker nodes
+	TopologyModeHighlyAvailable TopologyMode = "HighlyAvailable"
+	// TopologyModeSingleReplica is used on single node clusters
+	TopologyModeSingleReplica TopologyMode = "SingleReplica"
+)
+
+// Platform defines how the operator detects capabilities of the cluster
+// +kubebuilder:validation:Enum=Auto;OpenShift;Kubernetes
+type Platform string
+
+const (
+	// PlatformAuto detects capabilities from API groups served by the cluster
+	PlatformAuto Platform = "Auto"
+	// PlatformOpenShift assumes all OpenShift capabilities are available
+	PlatformOpenShift Platform = "OpenShift"
+	// PlatformKubernetes assumes no OpenShift capabilities are available
+	PlatformKubernetes Platform = "Kubernetes"
+)
+
+// AdoptionPolicy defines how the operator handles existing resources without its owner annotations or references
+// +kubebuilder:validation:Enum=Adopt;Skip;Fail
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt takes over existing resources and updates them to the desired state
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicySkip leaves existing resources untouched and reports them as conflicts
+	AdoptionPolicySkip AdoptionPolicy = "Skip"
+	// AdoptionPolicyFail leaves existing resources untouched and marks them as degraded
+	AdoptionPolicyFail AdoptionPolicy = "Fail"
+)
+
 // FeatureGates defines feature gate for tto operator
 type FeatureGates struct {
 	DeployTektonTaskResources bool `json:"deployTektonTaskResources,omitempty"`
@@ -50,6 +136,188 @@ type TektonTasksStatus struct {
 
 	// ObservedGeneration is the latest generation observed by the operator.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conflicts lists existing resources, that are not owned by the operator and were not adopted.
+	// +optional
+	Conflicts []ResourceConflict `json:"conflicts,omitempty"`
+
+	// LastPrune summarizes the last removal of resources, that are no longer part of the deployed bundle.
+	// +optional
+	LastPrune *PruneSummary `json:"lastPrune,omitempty"`
+
+	// Inventory lists resources deployed by the operator.
+	// +optional
+	Inventory []InventoryEntry `json:"inventory,omitempty"`
+
+	// Capabilities lists platform capabilities, that were used to select variants of deployed bundles.
+	// +optional
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// BundleVariant is the variant of tasks and pipelines bundles deployed by the operator.
+	// +optional
+	BundleVariant string `json:"bundleVariant,omitempty"`
+
+	// TopologyProfile is the infrastructure topology, that deployed resources were adapted for.
+	// +optional
+	TopologyProfile string `json:"topologyProfile,omitempty"`
+
+	// Skipped lists tasks and pipelines, that are not deployed, because API groups they depend on are not available.
+	// They are deployed once the API groups are installed.
+	// +optional
+	Skipped []SkippedResource `json:"skipped,omitempty"`
+
+	// Migrations records progress of upgrade migrations, so an interrupted upgrade resumes
+	// with the first migration, that has not completed.
+	// +optional
+	Migrations []MigrationStatus `json:"migrations,omitempty"`
+
+	// PendingUpdates lists ClusterTasks and Pipelines, whose updates are deferred, because running
+	// TaskRuns or PipelineRuns reference them.
+	// +optional
+	PendingUpdates []PendingUpdate `json:"pendingUpdates,omitempty"`
+
+	// NextUpdateWindow is the time, when the next update window opens. It is set only while
+	// spec.updateWindows are defined and none of them is open.
+	// +optional
+	NextUpdateWindow *metav1.Time `json:"nextUpdateWindow,omitempty"`
+}
+
+// ResourceReference identifies a resource managed by the operator
+type ResourceReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// ResourceConflict describes an existing resource, that is not owned by the operator
+type ResourceConflict struct {
+	ResourceReference `json:",inline"`
+
+	// Policy is the adoption policy, that was applied to the resource.
+	Policy AdoptionPolicy `json:"policy"`
+}
+
+// SkippedResource describes a resource, that is not deployed, because its dependencies are not available
+type SkippedResource struct {
+	ResourceReference `json:",inline"`
+
+	// Reason describes why the resource is not deployed.
+	Reason string `json:"reason"`
+
+	// MissingAPIGroups lists API groups, that the resource depends on and that are not available.
+	MissingAPIGroups []string `json:"missingAPIGroups"`
+}
+
+// MigrationStatus describes an upgrade migration run by the operator
+type MigrationStatus struct {
+	// Name identifies the migration.
+	Name string `json:"name"`
+
+	// FromVersion is the operator version, that deployed resources before the upgrade.
+	// +optional
+	FromVersion string `json:"fromVersion,omitempty"`
+
+	// ToVersion is the operator version, that introduced the migration.
+	ToVersion string `json:"toVersion"`
+
+	// Completed is true, when the migration was successfully applied. Completed migrations are not run again.
+	Completed bool `json:"completed"`
+
+	// CompletionTime is the time, when the migration was completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Error is the error of the last failed attempt.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PendingUpdate describes a resource, whose update is deferred
+type PendingUpdate struct {
+	ResourceReference `json:",inline"`
+
+	// Reason describes why the update is deferred.
+	Reason PendingUpdateReason `json:"reason"`
+
+	// Since is the time, when the update was deferred for the first time.
+	Since metav1.Time `json:"since"`
+
+	// Runs lists running TaskRuns and PipelineRuns, that reference the resource.
+	// At most 10 runs are listed.
+	// +optional
+	Runs []ResourceReference `json:"runs,omitempty"`
+
+	// RunCount is the number of running TaskRuns and PipelineRuns, that reference the resource.
+	// +optional
+	RunCount int `json:"runCount,omitempty"`
+}
+
+// PendingUpdateReason describes why an update is deferred
+// +kubebuilder:validation:Enum=RunsInProgress;OutsideUpdateWindow
+type PendingUpdateReason string
+
+const (
+	// PendingUpdateReasonRunsInProgress is used while running TaskRuns or PipelineRuns reference the resource
+	PendingUpdateReasonRunsInProgress PendingUpdateReason = "RunsInProgress"
+	// PendingUpdateReasonOutsideUpdateWindow is used while no update window is open
+	PendingUpdateReasonOutsideUpdateWindow PendingUpdateReason = "OutsideUpdateWindow"
+)
+
+// InventoryEntry describes a resource deployed by the operator
+type InventoryEntry struct {
+	ResourceReference `json:",inline"`
+
+	// Version is the tekton-tasks.kubevirt.io/version label of ClusterTasks,
+	// or the app.kubernetes.io/version label of other resources.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Images lists container images used by the resource.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// OperationResult is the result of the last reconciliation of the resource.
+	// +optional
+	OperationResult string `json:"operationResult,omitempty"`
+
+	// Health of the resource after the last reconciliation.
+	Health ResourceHealth `json:"health"`
+
+	// Message describes why the resource is not healthy.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// UID, ResourceVersion and Generation identify the version of the resource after the last reconciliation.
+	// The operator uses them after a restart to skip resources, that were not changed since.
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+	// +optional
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+}
+
+// ResourceHealth describes health of a resource deployed by the operator
+// +kubebuilder:validation:Enum=Healthy;Progressing;NotAvailable;Degraded
+type ResourceHealth string
+
+const (
+	ResourceHealthHealthy      ResourceHealth = "Healthy"
+	ResourceHealthProgressing  ResourceHealth = "Progressing"
+	ResourceHealthNotAvailable ResourceHealth = "NotAvailable"
+	ResourceHealthDegraded     ResourceHealth = "Degraded"
+)
+
+// PruneSummary describes resources, that were deleted because they are no longer part of the deployed bundle
+type PruneSummary struct {
+	// Time when the resources were pruned.
+	Time metav1.Time `json:"time"`
+
+	// OperatorVersion is the version of the operator, that pruned the resources.
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+
+	// Resources lists the pruned resources.
+	Resources []ResourceReference `json:"resources,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -6,6 +6,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -24,6 +25,68 @@ func (in *FeatureGates) DeepCopy() *FeatureGates {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryEntry) DeepCopyInto(out *InventoryEntry) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryEntry.
+func (in *InventoryEntry) DeepCopy() *InventoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStatus.
+func (in *MigrationStatus) DeepCopy() *MigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingUpdate) DeepCopyInto(out *PendingUpdate) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+	in.Since.DeepCopyInto(&out.Since)
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingUpdate.
+func (in *PendingUpdate) DeepCopy() *PendingUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pipelines) DeepCopyInto(out *Pipelines) {
 	*out = *in
@@ -39,12 +102,85 @@ func (in *Pipelines) DeepCopy() *Pipelines {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneSummary) DeepCopyInto(out *PruneSummary) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneSummary.
+func (in *PruneSummary) DeepCopy() *PruneSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceConflict) DeepCopyInto(out *ResourceConflict) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceConflict.
+func (in *ResourceConflict) DeepCopy() *ResourceConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReference.
+func (in *ResourceReference) DeepCopy() *ResourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedResource) DeepCopyInto(out *SkippedResource) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+	if in.MissingAPIGroups != nil {
+		in, out := &in.MissingAPIGroups, &out.MissingAPIGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedResource.
+func (in *SkippedResource) DeepCopy() *SkippedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TektonTasks) DeepCopyInto(out *TektonTasks) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -103,6 +239,12 @@ func (in *TektonTasksSpec) DeepCopyInto(out *TektonTasksSpec) {
 	*out = *in
 	out.Pipelines = in.Pipelines
 	out.FeatureGates = in.FeatureGates
+	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	if in.UpdateWindows != nil {
+		in, out := &in.UpdateWindows, &out.UpdateWindows
+		*out = make([]UpdateWindow, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TektonTasksSpec.
@@ -119,6 +261,53 @@ func (in *TektonTasksSpec) DeepCopy() *TektonTasksSpec {
 func (in *TektonTasksStatus) DeepCopyInto(out *TektonTasksStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]ResourceConflict, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastPrune != nil {
+		in, out := &in.LastPrune, &out.LastPrune
+		*out = new(PruneSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]InventoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]SkippedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]MigrationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingUpdates != nil {
+		in, out := &in.PendingUpdates, &out.PendingUpdates
+		*out = make([]PendingUpdate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextUpdateWindow != nil {
+		in, out := &in.NextUpdateWindow, &out.NextUpdateWindow
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TektonTasksStatus.
@@ -130,3 +319,39 @@ func (in *TektonTasksStatus) DeepCopy() *TektonTasksStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateStrategy) DeepCopyInto(out *UpdateStrategy) {
+	*out = *in
+	if in.MaxWait != nil {
+		in, out := &in.MaxWait, &out.MaxWait
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStrategy.
+func (in *UpdateStrategy) DeepCopy() *UpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateWindow) DeepCopyInto(out *UpdateWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateWindow.
+func (in *UpdateWindow) DeepCopy() *UpdateWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateWindow)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/monitoring"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
+)
+
+// cachedManagedTypes are watched cluster-wide. Only objects labeled as managed
+// by the operator are cached, other objects are read without the cache when needed.
+var cachedManagedTypes = []client.Object{
+	&v1.ConfigMap{},
+	&v1.ServiceAccount{},
+	&rbac.RoleBinding{},
+	&rbac.ClusterRole{},
+	&pipeline.ClusterTask{},
+	&pipeline.Pipeline{},
+}
+
+// NewCache creates a cache, that restricts informers of managed types to objects with the managed-by label,
+// and informers of runs to runs of ClusterTasks and Pipelines.
+func NewCache() cache.NewCacheFunc {
+	selector := cache.ObjectSelector{
+		Label: labels.SelectorFromSet(labels.Set{
+			common.AppKubernetesManagedByLabel: common.AppKubernetesManagedByValue,
+		}),
+	}
+
+	selectors := cache.SelectorsByObject{}
+	for _, obj := range cachedManagedTypes {
+		selectors[obj] = selector
+	}
+
+	// Run metrics only need runs of ClusterTasks and Pipelines. Tekton labels runs with names of their tasks and pipelines.
+	selectors[&pipeline.TaskRun{}] = labelExistsSelector(tektonpipeline.ClusterTaskLabelKey)
+	selectors[&pipeline.PipelineRun{}] = labelExistsSelector(tektonpipeline.PipelineLabelKey)
+	selectors[monitoring.NewPrometheusRuleType()] = selector
+	return cache.BuilderWithOptions(cache.Options{SelectorsByObject: selectors})
+}
+
+func labelExistsSelector(key string) cache.ObjectSelector {
+	requirement, err := labels.NewRequirement(key, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+	return cache.ObjectSelector{Label: labels.NewSelector().Add(*requirement)}
+}
+
+// validateCachedTypes checks that all types watched by operands are cached with the label selector.
+func validateCachedTypes(tektonOperands []operands.Operand) error {
+	cached := make(map[reflect.Type]bool, len(cachedManagedTypes))
+	for _, obj := range cachedManagedTypes {
+		cached[reflect.TypeOf(obj)] = true
+	}
+
+	for _, operand := range tektonOperands {
+		for _, obj := range append(operand.WatchClusterTypes(), operand.WatchTypes()...) {
+			if !cached[reflect.TypeOf(obj)] {
+				return fmt.Errorf("type %T watched by operand %s is not in the cached managed types", obj, operand.Name())
+			}
+		}
+	}
+	return nil
+}
@@ -3,68 +3,91 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
-	"github.com/kubevirt/tekton-tasks-operator/controllers/finishable"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
-func CreateCrdController(mgr controllerruntime.Manager, requiredCrds []string) (finishable.Controller, error) {
+// CreateCrdController adds a controller, that watches the required CRDs for the whole lifetime of the operator.
+// The dependent manager is enabled only while all required CRDs exist.
+func CreateCrdController(mgr controllerruntime.Manager, requiredCrds []string, foundCrds []extv1.CustomResourceDefinition,
+	dependents *dependentManager, readiness *common.Readiness) error {
+
 	crds := make(map[string]bool, len(requiredCrds))
 	for _, crd := range requiredCrds {
 		crds[crd] = false
 	}
-
-	reconciler := &waitForCrds{
-		client: mgr.GetClient(),
-		crds:   crds,
+	for i := range foundCrds {
+		if _, required := crds[foundCrds[i].Name]; required {
+			crds[foundCrds[i].Name] = foundCrds[i].GetDeletionTimestamp().IsZero()
+		}
 	}
 
-	initCtrl, err := finishable.NewController("init-controller", mgr, reconciler)
-	if err != nil {
-		return nil, err
+	reconciler := &watchCrds{
+		client:     mgr.GetClient(),
+		recorder:   mgr.GetEventRecorderFor(common.EventSource),
+		crds:       crds,
+		dependents: dependents,
+		readiness:  readiness,
 	}
-
-	err = initCtrl.Watch(&source.Kind{Type: &extv1.CustomResourceDefinition{}}, &handler.EnqueueRequestForObject{})
+	reconciler.lastMissing = strings.Join(reconciler.missingCrds(), ", ")
+
+	// Initial state is known before the manager starts, so the condition does not flap
+	// while the watch reports existing CRDs one by one
+	allExist := reconciler.allCrdsExist()
+	common.TektonOperatorMissingCrds.Set(float64(len(reconciler.missingCrds())))
+	readiness.SetCrdsPresent(allExist)
+	err := dependents.SetEnabled(allExist)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return initCtrl, nil
+	// All CRDs are watched, so missing CRDs are reported on start, when none of the required CRDs exist
+	return controllerruntime.NewControllerManagedBy(mgr).
+		Named("crd-controller").
+		For(&extv1.CustomResourceDefinition{}).
+		Complete(reconciler)
 }
 
-type waitForCrds struct {
-	client client.Client
+type watchCrds struct {
+	client     client.Client
+	recorder   record.EventRecorder
+	dependents *dependentManager
+	readiness  *common.Readiness
 
 	lock sync.RWMutex
 	crds map[string]bool
-}
 
-var _ finishable.Reconciler = &waitForCrds{}
+	// reportedMissing holds the missing CRDs reported in the last event
+	reportedMissing string
+	// lastMissing holds the missing CRDs, that were handled by the last reconcile
+	lastMissing string
+}
 
-func (w *waitForCrds) Reconcile(ctx context.Context, request reconcile.Request) (finishable.Result, error) {
-	err := setConditions(w.client)
-	if err != nil {
-		return finishable.Result{}, err
-	}
+var _ reconcile.Reconciler = &watchCrds{}
 
+func (w *watchCrds) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	crdExists := true
 	crd := &extv1.CustomResourceDefinition{}
-	err = w.client.Get(ctx, request.NamespacedName, crd)
+	err := w.client.Get(ctx, request.NamespacedName, crd)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return finishable.Result{}, err
+			return reconcile.Result{}, err
 		}
 		crdExists = false
 	}
@@ -79,10 +102,79 @@ func (w *waitForCrds) Reconcile(ctx context.Context, request reconcile.Request)
 		w.setCrdExists(key, crdExists)
 	}
 
-	return finishable.Result{Finished: w.allCrdsExist()}, nil
+	missingCrds := w.missingCrds()
+	missing := strings.Join(missingCrds, ", ")
+	if missing == "" && w.lastMissing == "" {
+		// All required CRDs still exist, the status is maintained by the dependent controllers
+		return reconcile.Result{}, nil
+	}
+
+	common.TektonOperatorMissingCrds.Set(float64(len(missingCrds)))
+	w.readiness.SetCrdsPresent(len(missingCrds) == 0)
+
+	if len(missingCrds) > 0 {
+		common.TektonOperatorReconcilingProperly.Set(0)
+		// Dependent controllers are stopped before the condition is set, so they do not overwrite it
+		err = w.dependents.SetEnabled(false)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	instance, err := setConditions(w.client, missingCrds)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if instance != nil {
+		w.reportMissingCrds(instance)
+	}
+
+	if len(missingCrds) == 0 {
+		err = w.dependents.SetEnabled(true)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if instance != nil {
+			w.dependents.Requeue(instance)
+		}
+	}
+
+	w.lastMissing = missing
+	return reconcile.Result{}, nil
 }
 
-func (w *waitForCrds) isCrdRequired(key string) bool {
+// reportMissingCrds emits an event on the TektonTasks CR when the set of missing CRDs changes.
+func (w *watchCrds) reportMissingCrds(instance *tekton.TektonTasks) {
+	missing := strings.Join(w.missingCrds(), ", ")
+	if w.recorder == nil || missing == w.reportedMissing {
+		return
+	}
+	w.reportedMissing = missing
+
+	if missing == "" {
+		w.recorder.Event(instance, v1.EventTypeNormal, common.EventReasonCRDsInstalled,
+			"Required CRDs were installed, resuming reconciliation")
+		return
+	}
+	w.recorder.Event(instance, v1.EventTypeWarning, common.EventReasonWaitingForCRDs,
+		fmt.Sprintf("Waiting for required CRDs to be installed: %s", missing))
+}
+
+func (w *watchCrds) missingCrds() []string {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	var missing []string
+	for crd, exists := range w.crds {
+		if !exists {
+			missing = append(missing, crd)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (w *watchCrds) isCrdRequired(key string) bool {
 	w.lock.RLock()
 	defer w.lock.RUnlock()
 
@@ -90,14 +182,14 @@ func (w *waitForCrds) isCrdRequired(key string) bool {
 	return exists
 }
 
-func (w *waitForCrds) setCrdExists(key string, val bool) {
+func (w *watchCrds) setCrdExists(key string, val bool) {
 	w.lock.Lock()
 	defer w.lock.Unlock()
 
 	w.crds[key] = val
 }
 
-func (w *waitForCrds) allCrdsExist() bool {
+func (w *watchCrds) allCrdsExist() bool {
 	w.lock.RLock()
 	defer w.lock.RUnlock()
 
@@ -108,25 +200,36 @@ func (w *waitForCrds) allCrdsExist() bool {
 	return allExist
 }
 
-func setConditions(cli client.Client) error {
+// setConditions updates status of the TektonTasks CR and returns it, if it exists.
+func setConditions(cli client.Client, missingCrds []string) (*tekton.TektonTasks, error) {
 	operatorNamespace := environment.GetOperatorNamespace()
 	instances := &tekton.TektonTasksList{}
 	err := cli.List(context.TODO(), instances, client.InNamespace(operatorNamespace))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(instances.Items) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	if len(instances.Items) > 1 {
-		return errors.New("multiple TektonTasks CRs in single namespace")
+		return nil, errors.New("multiple TektonTasks CRs in single namespace")
 	}
 
-	err = updateStatus(&common.Request{Instance: &instances.Items[0], Client: cli, Context: context.TODO()}, []common.ReconcileResult{})
+	request := &common.Request{Instance: &instances.Items[0], Client: cli, Context: context.TODO()}
+	originalStatus := request.Instance.Status.DeepCopy()
+	if len(missingCrds) > 0 {
+		setMainConditions(&request.Instance.Status.Conditions, v1.ConditionFalse, v1.ConditionTrue, v1.ConditionTrue,
+			tekton.ReasonCRDMissing, fmt.Sprintf("Waiting for required CRDs to be installed: %s", strings.Join(missingCrds, ", ")))
+	} else if degraded := conditionsv1.FindStatusCondition(request.Instance.Status.Conditions, conditionsv1.ConditionDegraded); degraded != nil && degraded.Reason == tekton.ReasonCRDMissing {
+		// Only the missing CRDs are cleared, the rest of the status is computed by the following reconcile
+		setMainConditions(&request.Instance.Status.Conditions, v1.ConditionFalse, v1.ConditionTrue, v1.ConditionTrue,
+			tekton.ReasonReconciling, "Required CRDs were installed, reconciling Tekton tasks resources")
+	}
+	err = writeStatus(request, originalStatus)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return &instances.Items[0], nil
 }
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// dependentManager runs controllers, that depend on CRDs, in a separate manager with its own cache.
+// The manager is stopped when the CRDs are removed, so the informers of removed types do not fail,
+// and a new one is started when the CRDs are installed again.
+type dependentManager struct {
+	newManager func() (manager.Manager, error)
+	setup      func(manager.Manager, <-chan event.GenericEvent) error
+	log        logr.Logger
+	// requeue passes objects to be reconciled to the controllers of the running manager
+	requeue chan event.GenericEvent
+
+	lock    sync.Mutex
+	enabled bool
+	// ctx is the context of the parent manager, it is nil until the parent manager starts this runnable
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	errs   chan error
+}
+
+var _ manager.Runnable = &dependentManager{}
+
+func newDependentManager(parent manager.Manager, name string, setup func(manager.Manager, <-chan event.GenericEvent) error) *dependentManager {
+	return &dependentManager{
+		newManager: func() (manager.Manager, error) {
+			return controllerruntime.NewManager(parent.GetConfig(), dependentManagerOptions(parent.GetScheme()))
+		},
+		setup:   setup,
+		log:     parent.GetLogger().WithName(name),
+		requeue: make(chan event.GenericEvent, 1),
+		errs:    make(chan error, 1),
+	}
+}
+
+func dependentManagerOptions(scheme *runtime.Scheme) manager.Options {
+	return manager.Options{
+		Scheme:   scheme,
+		NewCache: NewCache(),
+		// Metrics and probes are served by the parent manager
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: "0",
+		// The parent manager starts this manager only when it is the leader
+		LeaderElection: false,
+	}
+}
+
+// Start runs the dependent manager while it is enabled, until the context is cancelled.
+func (d *dependentManager) Start(ctx context.Context) error {
+	d.lock.Lock()
+	d.ctx = ctx
+	err := d.apply()
+	d.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		err = nil
+	case err = <-d.errs:
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.stop()
+	return err
+}
+
+// SetEnabled starts or stops the dependent manager. Stopping waits until all its controllers are stopped.
+// A running manager is kept running, when it is enabled again.
+func (d *dependentManager) SetEnabled(enabled bool) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.enabled = enabled
+	return d.apply()
+}
+
+func (d *dependentManager) apply() error {
+	if d.ctx == nil || d.ctx.Err() != nil {
+		return nil
+	}
+	if !d.enabled {
+		d.stop()
+		return nil
+	}
+	if d.cancel != nil {
+		return nil
+	}
+
+	mgr, err := d.newManager()
+	if err != nil {
+		return err
+	}
+	err = d.setup(mgr, d.requeue)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := mgr.Start(ctx); err != nil {
+			select {
+			case d.errs <- err:
+			default:
+			}
+		}
+	}()
+
+	d.log.Info("Started controllers depending on CRDs")
+	d.cancel = cancel
+	d.done = done
+	return nil
+}
+
+// Requeue reconciles the object by the controllers of the dependent manager. The request is dropped,
+// if another one is pending, or when the manager is stopped, because a started manager reconciles all objects.
+func (d *dependentManager) Requeue(obj client.Object) {
+	select {
+	case d.requeue <- event.GenericEvent{Object: obj}:
+	default:
+	}
+}
+
+func (d *dependentManager) stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+	d.cancel = nil
+	d.done = nil
+	d.log.Info("Stopped controllers depending on CRDs")
+}
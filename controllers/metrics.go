@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// reportManagedResources sets the managed resources gauge from the inventory.
+// The gauge is reset first, so kinds and states without resources are not reported.
+func reportManagedResources(inventory []tekton.InventoryEntry) {
+	common.TektonOperatorManagedResources.Reset()
+	for _, entry := range inventory {
+		common.TektonOperatorManagedResources.WithLabelValues(entry.Kind, string(entry.Health)).Inc()
+	}
+}
+
+// reportDegraded sets the degraded gauge from the Degraded condition of the TektonTasks CR.
+func reportDegraded(instance *tekton.TektonTasks) {
+	if conditionsv1.IsStatusConditionTrue(instance.Status.Conditions, conditionsv1.ConditionDegraded) {
+		common.TektonOperatorCRDegraded.Set(1)
+	} else {
+		common.TektonOperatorCRDegraded.Set(0)
+	}
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/migrations"
+)
+
+// newUpgradeMigrations returns migrations, that are run once before operands are reconciled after an upgrade.
+func newUpgradeMigrations() (*migrations.Registry, error) {
+	return migrations.NewRegistry(
+		migrations.Migration{
+			// Versions up to 0.1.2 renamed the finalizer on each reconcile, so CRs observed by 0.1.2 use the new one
+			Name:      "rename-finalizer",
+			ToVersion: "0.1.2",
+			Migrate:   renameFinalizer,
+		},
+	)
+}
+
+func renameFinalizer(request *common.Request) error {
+	if !controllerutil.ContainsFinalizer(request.Instance, oldFinalizerName) {
+		return nil
+	}
+	controllerutil.RemoveFinalizer(request.Instance, oldFinalizerName)
+	controllerutil.AddFinalizer(request.Instance, finalizerName)
+	return updateTektonTasksResource(request)
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/monitoring"
+)
+
+// setupMonitoringController adds a controller to the main manager, that deploys the PrometheusRule.
+// It runs independently of the TektonTasks CR and Tekton CRDs, so alerts about missing CRDs can fire.
+// The controller of the rule runs in a dependent manager, that is enabled only while the PrometheusRule CRD exists,
+// so the informer of rules is stopped when the CRD is removed.
+func setupMonitoringController(mgr ctrl.Manager) error {
+	rules := newDependentManager(mgr, "monitoring-manager", setupRuleController)
+
+	isRuleCrd := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == monitoring.PrometheusRuleCRD
+	})
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named("monitoring-crd-controller").
+		For(&extv1.CustomResourceDefinition{}, builder.WithPredicates(isRuleCrd)).
+		Complete(&monitoringCrdReconciler{
+			client: mgr.GetClient(),
+			rules:  rules,
+		})
+	if err != nil {
+		return err
+	}
+	return mgr.Add(rules)
+}
+
+// monitoringCrdReconciler enables the controller of the PrometheusRule, while the PrometheusRule CRD exists.
+type monitoringCrdReconciler struct {
+	client client.Client
+	rules  *dependentManager
+}
+
+var _ reconcile.Reconciler = &monitoringCrdReconciler{}
+
+func (m *monitoringCrdReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	crd := &extv1.CustomResourceDefinition{}
+	err := m.client.Get(ctx, request.NamespacedName, crd)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	crdExists := err == nil && crd.GetDeletionTimestamp().IsZero()
+	if !crdExists {
+		ctrl.LoggerFrom(ctx).V(1).Info(fmt.Sprintf("CRD %s is not installed, skipping alerting rules", monitoring.PrometheusRuleCRD))
+	}
+	err = m.rules.SetEnabled(crdExists)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if crdExists {
+		// The rule does not exist on first install, so its watch does not trigger the first reconcile
+		rule := monitoring.NewPrometheusRuleType()
+		rule.SetNamespace(environment.GetOperatorNamespace())
+		rule.SetName(monitoring.PrometheusRuleName)
+		m.rules.Requeue(rule)
+	}
+	return reconcile.Result{}, nil
+}
+
+// setupRuleController adds a controller to the dependent manager, that creates the PrometheusRule
+// and reverts changes of it immediately.
+func setupRuleController(mgr manager.Manager, requeue <-chan event.GenericEvent) error {
+	ruleKey := types.NamespacedName{Namespace: environment.GetOperatorNamespace(), Name: monitoring.PrometheusRuleName}
+	enqueueRule := handler.EnqueueRequestsFromMapFunc(func(_ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: ruleKey}}
+	})
+
+	ruleController, err := controller.New("monitoring-controller", mgr, controller.Options{
+		Reconciler: &monitoringReconciler{client: mgr.GetClient()},
+	})
+	if err != nil {
+		return err
+	}
+
+	isRule := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == ruleKey.Namespace && obj.GetName() == ruleKey.Name
+	})
+	err = ruleController.Watch(&source.Kind{Type: monitoring.NewPrometheusRuleType()}, enqueueRule, isRule)
+	if err != nil {
+		return err
+	}
+	return ruleController.Watch(&source.Channel{Source: requeue}, enqueueRule)
+}
+
+type monitoringReconciler struct {
+	client client.Client
+}
+
+var _ reconcile.Reconciler = &monitoringReconciler{}
+
+func (m *monitoringReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	operation, err := monitoring.ReconcileRule(ctx, m.client, request.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if operation != controllerutil.OperationResultNone {
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("PrometheusRule %s %s", request.NamespacedName, operation))
+	}
+	return reconcile.Result{}, nil
+}
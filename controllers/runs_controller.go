@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// +kubebuilder:rbac:groups=tekton.dev,resources=taskruns;pipelineruns,verbs=get;list;watch;patch
+
+// runRecordedAnnotation is set on runs counted in metrics, so each run is counted once.
+// The value is the completion time of the run.
+const runRecordedAnnotation = "tekton-tasks.kubevirt.io/metrics-recorded"
+
+// setupRunMetricsControllers adds controllers, that export metrics of TaskRuns and PipelineRuns,
+// which use ClusterTasks and Pipelines managed by the operator.
+func setupRunMetricsControllers(mgr ctrl.Manager) error {
+	isDone := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		switch run := obj.(type) {
+		case *pipeline.TaskRun:
+			return run.IsDone()
+		case *pipeline.PipelineRun:
+			return run.IsDone()
+		}
+		return false
+	})
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named("taskrun-metrics").
+		For(&pipeline.TaskRun{}).
+		WithEventFilter(isDone).
+		Complete(&taskRunMetricsReconciler{client: mgr.GetClient(), runs: newRecordedRuns()})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pipelinerun-metrics").
+		For(&pipeline.PipelineRun{}).
+		WithEventFilter(isDone).
+		Complete(&pipelineRunMetricsReconciler{client: mgr.GetClient(), runs: newRecordedRuns()})
+}
+
+// recordedRuns counts each finished run once. Counted runs are annotated, and they are remembered only
+// until the annotation is visible in the cache, so the memory does not grow with the number of runs.
+// Runs finished before the operator started are not counted, so a restart does not count them again.
+type recordedRuns struct {
+	startTime time.Time
+
+	lock sync.Mutex
+	// pending holds runs, that were counted, but their annotation is not in the cache yet
+	pending map[types.NamespacedName]types.UID
+}
+
+func newRecordedRuns() *recordedRuns {
+	return &recordedRuns{
+		startTime: time.Now(),
+		pending:   map[types.NamespacedName]types.UID{},
+	}
+}
+
+// record calls count, if the run finished after the operator started and was not counted yet,
+// and annotates the run as recorded.
+func (r *recordedRuns) record(ctx context.Context, cli client.Client, run client.Object, completionTime *metav1.Time, count func()) error {
+	if completionTime == nil || completionTime.Time.Before(r.startTime) {
+		return nil
+	}
+
+	key := client.ObjectKeyFromObject(run)
+	if _, recorded := run.GetAnnotations()[runRecordedAnnotation]; recorded {
+		r.forget(key)
+		return nil
+	}
+	if r.markPending(key, run.GetUID()) {
+		count()
+	}
+
+	original := run.DeepCopyObject().(client.Object)
+	annotations := run.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[runRecordedAnnotation] = completionTime.UTC().Format(time.RFC3339)
+	run.SetAnnotations(annotations)
+	err := cli.Patch(ctx, run, client.MergeFrom(original))
+	if errors.IsNotFound(err) {
+		r.forget(key)
+		return nil
+	}
+	return err
+}
+
+// markPending returns true, if the run was not counted yet.
+func (r *recordedRuns) markPending(key types.NamespacedName, uid types.UID) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.pending[key] == uid {
+		return false
+	}
+	r.pending[key] = uid
+	return true
+}
+
+func (r *recordedRuns) forget(key types.NamespacedName) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.pending, key)
+}
+
+type taskRunMetricsReconciler struct {
+	client client.Client
+	runs   *recordedRuns
+}
+
+func (r *taskRunMetricsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	taskRun := &pipeline.TaskRun{}
+	err := r.client.Get(ctx, req.NamespacedName, taskRun)
+	if errors.IsNotFound(err) {
+		r.runs.forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	taskRef := taskRun.Spec.TaskRef
+	if !taskRun.IsDone() || taskRef == nil || taskRef.Kind != pipeline.ClusterTaskKind {
+		return ctrl.Result{}, nil
+	}
+
+	managed, err := isManagedBy(ctx, r.client, client.ObjectKey{Name: taskRef.Name}, &pipeline.ClusterTask{}, common.AppComponentTektonTasks)
+	if err != nil || !managed {
+		return ctrl.Result{}, err
+	}
+
+	err = r.runs.record(ctx, r.client, taskRun, taskRun.Status.CompletionTime, func() {
+		status := runStatus(taskRun.Status.GetCondition(apis.ConditionSucceeded), taskRun.IsCancelled())
+		common.TektonOperatorTaskRuns.WithLabelValues(taskRef.Name, status).Inc()
+		common.TektonOperatorTaskRunDuration.WithLabelValues(taskRef.Name, status).
+			Observe(runDuration(taskRun, taskRun.Status.StartTime, taskRun.Status.CompletionTime).Seconds())
+	})
+	return ctrl.Result{}, err
+}
+
+type pipelineRunMetricsReconciler struct {
+	client client.Client
+	runs   *recordedRuns
+}
+
+func (r *pipelineRunMetricsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pipelineRun := &pipeline.PipelineRun{}
+	err := r.client.Get(ctx, req.NamespacedName, pipelineRun)
+	if errors.IsNotFound(err) {
+		r.runs.forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pipelineRef := pipelineRun.Spec.PipelineRef
+	if !pipelineRun.IsDone() || pipelineRef == nil || pipelineRef.Name == "" {
+		return ctrl.Result{}, nil
+	}
+
+	key := client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRef.Name}
+	managed, err := isManagedBy(ctx, r.client, key, &pipeline.Pipeline{}, common.AppComponentTektonPipelines)
+	if err != nil || !managed {
+		return ctrl.Result{}, err
+	}
+
+	err = r.runs.record(ctx, r.client, pipelineRun, pipelineRun.Status.CompletionTime, func() {
+		status := runStatus(pipelineRun.Status.GetCondition(apis.ConditionSucceeded), pipelineRun.IsCancelled())
+		common.TektonOperatorPipelineRuns.WithLabelValues(pipelineRef.Name, status).Inc()
+		common.TektonOperatorPipelineRunDuration.WithLabelValues(pipelineRef.Name, status).
+			Observe(runDuration(pipelineRun, pipelineRun.Status.StartTime, pipelineRun.Status.CompletionTime).Seconds())
+	})
+	return ctrl.Result{}, err
+}
+
+// isManagedBy returns true, if the referenced object exists and is deployed by the operator as part of the component.
+func isManagedBy(ctx context.Context, cli client.Client, key client.ObjectKey, obj client.Object, component common.AppComponent) (bool, error) {
+	err := cli.Get(ctx, key, obj)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	labels := obj.GetLabels()
+	return labels[common.AppKubernetesManagedByLabel] == common.AppKubernetesManagedByValue &&
+		labels[common.AppKubernetesComponentLabel] == component.String(), nil
+}
+
+func runStatus(succeeded *apis.Condition, cancelled bool) string {
+	switch {
+	case succeeded.IsTrue():
+		return common.MetricRunStatusSucceeded
+	case cancelled:
+		return common.MetricRunStatusCancelled
+	default:
+		return common.MetricRunStatusFailed
+	}
+}
+
+func runDuration(run client.Object, startTime, completionTime *metav1.Time) time.Duration {
+	start := run.GetCreationTimestamp().Time
+	if startTime != nil {
+		start = startTime.Time
+	}
+	return completionTime.Sub(start)
+}
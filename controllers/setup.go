@@ -2,34 +2,136 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
-	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
-
+	v1 "k8s.io/api/core/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/discovery"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
 	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
 	tektonpipelines "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-pipelines"
 	tektontasks "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-tasks"
-	controllerruntime "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Loading of bundles is retried with exponential backoff, up to this delay between attempts
+const (
+	bundleLoadInitialDelay = 5 * time.Second
+	bundleLoadMaxDelay     = 5 * time.Minute
 )
 
 func CreateAndSetupReconciler(mgr controllerruntime.Manager) error {
-	reader := mgr.GetAPIReader()
-	ctx := context.Background()
-	ttTasksBundle, err := tektonbundle.ReadTasksBundle(reader, ctx)
+	readiness, err := addReadyzChecks(mgr)
 	if err != nil {
 		return err
 	}
-	ttPipelinesBundle, err := tektonbundle.ReadPipelineBundle(reader, ctx)
+
+	err = setupMonitoringController(mgr)
 	if err != nil {
 		return err
 	}
 
-	tektonOperands := []operands.Operand{
-		tektontasks.New(ttTasksBundle),
-		tektonpipelines.New(ttPipelinesBundle),
+	// Bundles are loaded after the manager starts, so a bundle that cannot be loaded
+	// is reported by metrics, readiness and conditions, instead of crashing the operator.
+	return mgr.Add(&bundleLoader{mgr: mgr, readiness: readiness})
+}
+
+// bundleLoader loads the tasks and pipelines bundles, until it succeeds, and then sets up the controllers.
+type bundleLoader struct {
+	mgr       controllerruntime.Manager
+	readiness *common.Readiness
+}
+
+var _ manager.LeaderElectionRunnable = &bundleLoader{}
+
+// NeedLeaderElection returns false, because the readiness of replicas that are not the leader depends on the bundles.
+func (b *bundleLoader) NeedLeaderElection() bool {
+	return false
+}
+
+func (b *bundleLoader) Start(ctx context.Context) error {
+	delay := bundleLoadInitialDelay
+	for {
+		tasksBundles, pipelinesBundles, err := loadBundles()
+		if err == nil {
+			b.readiness.SetBundlesLoaded()
+			return setupOperands(b.mgr, b.readiness, tasksBundles, pipelinesBundles)
+		}
+
+		b.mgr.GetLogger().Error(err, "Failed to load bundles, retrying", "retry_after", delay.String())
+		reportBundleLoadFailure(b.mgr, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > bundleLoadMaxDelay {
+			delay = bundleLoadMaxDelay
+		}
+	}
+}
+
+// loadBundles reads the tasks and pipelines bundles, and counts failures of each of them.
+func loadBundles() (tasksBundles, pipelinesBundles map[tektonbundle.Variant]*tektonbundle.Bundle, err error) {
+	tasksBundles, err = tektonbundle.ReadTasksBundles()
+	if err != nil {
+		common.TektonOperatorBundleLoadFailures.WithLabelValues("tasks").Inc()
+		return nil, nil, err
+	}
+	pipelinesBundles, err = tektonbundle.ReadPipelineBundles()
+	if err != nil {
+		common.TektonOperatorBundleLoadFailures.WithLabelValues("pipelines").Inc()
+		return nil, nil, err
 	}
+	return tasksBundles, pipelinesBundles, nil
+}
+
+// setupOperands adds the controllers of the operands from the loaded bundles to the manager.
+func setupOperands(mgr controllerruntime.Manager, readiness *common.Readiness,
+	ttTasksBundles, ttPipelinesBundles map[tektonbundle.Variant]*tektonbundle.Bundle) error {
+	tektonOperands, err := operands.SortByDependencies([]operands.Operand{
+		bundleVariants(ttTasksBundles, func(bundle *tektonbundle.Bundle) operands.Operand {
+			return tektontasks.New(bundle)
+		}),
+		bundleVariants(ttPipelinesBundles, func(bundle *tektonbundle.Bundle) operands.Operand {
+			return tektonpipelines.New(bundle)
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = validateCachedTypes(tektonOperands)
+	if err != nil {
+		return err
+	}
+
+	upgradeMigrations, err := newUpgradeMigrations()
+	if err != nil {
+		return err
+	}
+
+	// The OperatorCondition is not labeled as managed by the operator, so it is not cached
+	uncachedClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	upgradeable, err := common.NewUpgradeableCondition(common.NewTracingClient(uncachedClient))
+	if err != nil {
+		return err
+	}
+
+	common.TektonOperatorInfo.WithLabelValues(environment.GetOperatorVersion(), operands.TektonTasksVersion).Set(1)
 
 	var requiredCrds []string
 	for i := range tektonOperands {
@@ -43,37 +145,65 @@ func CreateAndSetupReconciler(mgr controllerruntime.Manager) error {
 		return err
 	}
 
-	reconciler := NewTektonReconciler(mgr.GetClient(), mgr.GetAPIReader(), tektonOperands)
-
-	if requiredCrdsExist(requiredCrds, crdList.Items) {
-		// No need to start CRD controller
-		return reconciler.setupController(mgr)
+	// Controllers of the dependent manager use a new cache and new clients, each time the CRDs are installed
+	dependents := newDependentManager(mgr, "dependent-manager", func(dependentMgr manager.Manager, requeue <-chan event.GenericEvent) error {
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(dependentMgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		reconciler := NewTektonReconciler(
+			common.NewTracingClient(dependentMgr.GetClient()),
+			common.NewTracingReader(dependentMgr.GetAPIReader()),
+			discoveryClient,
+			dependentMgr.GetEventRecorderFor(common.EventSource),
+			tektonOperands,
+			upgradeMigrations,
+			upgradeable,
+			readiness,
+		)
+		return reconciler.setupController(dependentMgr, requeue)
+	})
+
+	if !requiredCrdsExist(requiredCrds, crdList.Items) {
+		mgr.GetLogger().Info("Required CRDs do not exist. Waiting until they are installed.",
+			"required_crds", requiredCrds,
+		)
 	}
 
-	mgr.GetLogger().Info("Required CRDs do not exist. Waiting until they are installed.",
-		"required_crds", requiredCrds,
-	)
-
-	crdController, err := CreateCrdController(mgr, requiredCrds)
+	err = CreateCrdController(mgr, requiredCrds, crdList.Items, dependents, readiness)
 	if err != nil {
 		return err
 	}
 
-	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
-		// First start the CRD controller
-		err := crdController.Start(ctx)
-		if err != nil {
-			return err
-		}
+	return mgr.Add(dependents)
+}
 
-		mgr.GetLogger().Info("Required CRDs were installed, starting tekton-tasks operator.")
+// bundleVariants returns an operand, that deploys the bundle variant selected by capabilities of the cluster.
+func bundleVariants(bundles map[tektonbundle.Variant]*tektonbundle.Bundle, newOperand func(*tektonbundle.Bundle) operands.Operand) operands.Operand {
+	variants := make(map[string]operands.Operand, len(bundles))
+	for variant, bundle := range bundles {
+		variants[string(variant)] = newOperand(bundle)
+	}
+	return operands.NewVariants(variants, func(request *common.Request) string {
+		return string(tektonbundle.SelectVariant(request.Capabilities))
+	})
+}
 
-		// Clear variable, so it can be garbage collected
-		crdController = nil
+// addReadyzChecks registers each readiness check separately, so it is available under /readyz/<name>.
+func addReadyzChecks(mgr controllerruntime.Manager) (*common.Readiness, error) {
+	reconcileWindow := environment.GetReconcileWindow()
+	if environment.GetResyncPeriod() == 0 {
+		// Without periodic reconciliation, the age of the last reconcile says nothing about the operator
+		reconcileWindow = 0
+	}
 
-		// After it is finished, add the tekton controller to the manager
-		return reconciler.setupController(mgr)
-	}))
+	readiness := common.NewReadiness(reconcileWindow, mgr.Elected())
+	for name, check := range readiness.Checks() {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			return nil, err
+		}
+	}
+	return readiness, nil
 }
 
 func requiredCrdsExist(required []string, foundCrds []extv1.CustomResourceDefinition) bool {
@@ -88,3 +218,26 @@ OuterLoop:
 	}
 	return true
 }
+
+// reportBundleLoadFailure sets conditions of existing TektonTasks CRs, so the failure
+// is visible without reading operator logs. Errors are only logged, because loading of bundles is retried anyway.
+func reportBundleLoadFailure(mgr controllerruntime.Manager, bundleErr error) {
+	ctx := context.Background()
+	instances := &tekton.TektonTasksList{}
+	err := mgr.GetAPIReader().List(ctx, instances, client.InNamespace(environment.GetOperatorNamespace()))
+	if err != nil {
+		mgr.GetLogger().Error(err, "Failed to list TektonTasks CRs")
+		return
+	}
+
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		originalStatus := instance.Status.DeepCopy()
+		setMainConditions(&instance.Status.Conditions, v1.ConditionFalse, v1.ConditionFalse, v1.ConditionTrue,
+			tekton.ReasonBundleLoadFailed, fmt.Sprintf("Failed to load bundle: %v", bundleErr))
+		err = patchStatus(ctx, mgr.GetClient(), instance, originalStatus)
+		if err != nil {
+			mgr.GetLogger().Error(err, "Failed to update TektonTasks status")
+		}
+	}
+}
@@ -20,31 +20,46 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	libhandler "github.com/operator-framework/operator-lib/handler"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/migrations"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
+	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
+	tektontasks "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-tasks"
 )
 
 const (
@@ -54,34 +69,53 @@ const (
 
 // tektonTasksReconciler reconciles a TektonTasks object
 type tektonTasksReconciler struct {
-	client              client.Client
-	log                 logr.Logger
-	operands            []operands.Operand
-	subresourceCache    common.VersionCache
-	lastTektonTasksSpec tekton.TektonTasksSpec
+	client         client.Client
+	uncachedReader client.Reader
+	log            logr.Logger
+	recorder       record.EventRecorder
+	discovery      discovery.ServerGroupsInterface
+	operands       []operands.Operand
+	migrations     *migrations.Registry
+	upgradeable    *common.UpgradeableCondition
+	readiness      *common.Readiness
+	// subresourceCaches hold a VersionCache per operand. Changes of the desired state
+	// are detected using the desired state hash, so the caches do not need to be cleared on spec change.
+	subresourceCaches map[string]common.VersionCache
 }
 
-func NewTektonReconciler(client client.Client, uncachedReader client.Reader, operands []operands.Operand) *tektonTasksReconciler {
+func NewTektonReconciler(client client.Client, uncachedReader client.Reader, discovery discovery.ServerGroupsInterface,
+	recorder record.EventRecorder, operands []operands.Operand, migrations *migrations.Registry, upgradeable *common.UpgradeableCondition,
+	readiness *common.Readiness) *tektonTasksReconciler {
 	return &tektonTasksReconciler{
-		client:           client,
-		subresourceCache: common.VersionCache{},
-		operands:         operands,
-		log:              ctrl.Log.WithName("controllers").WithName("TektonTasksOperator"),
+		client:            client,
+		uncachedReader:    uncachedReader,
+		discovery:         discovery,
+		recorder:          recorder,
+		readiness:         readiness,
+		subresourceCaches: map[string]common.VersionCache{},
+		operands:          operands,
+		migrations:        migrations,
+		upgradeable:       upgradeable,
+		log:               ctrl.Log.WithName("controllers").WithName("TektonTasksOperator"),
 	}
 }
 
 //+kubebuilder:rbac:groups=tektontasks.kubevirt.io,resources=tektontasks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=tektontasks.kubevirt.io,resources=tektontasks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=tektontasks.kubevirt.io,resources=tektontasks/finalizers,verbs=update
-//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *tektonTasksReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, err error) {
+	ctx, span := common.StartContextSpan(ctx, "Reconcile",
+		common.NamespaceAttribute.String(req.Namespace),
+		common.NameAttribute.String(req.Name))
 	defer func() {
 		if err != nil {
 			common.TektonOperatorReconcilingProperly.Set(0)
 		}
+		common.EndSpan(span, err)
 	}()
 	reqLogger := r.log.WithValues("tekton-task-operator", req.NamespacedName)
 	reqLogger.V(1).Info("Starting reconciliation...")
@@ -93,33 +127,44 @@ func (r *tektonTasksReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.readiness.NothingToReconcile()
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 
-	r.clearCacheIfNeeded(instance)
-
 	tektonRequest := &common.Request{
-		Request:      req,
-		Client:       r.client,
-		Context:      ctx,
-		Instance:     instance,
-		Logger:       reqLogger,
-		VersionCache: r.subresourceCache,
+		Request:        req,
+		Client:         r.client,
+		UncachedReader: r.uncachedReader,
+		Context:        ctx,
+		Instance:       instance,
+		Logger:         reqLogger,
+		Recorder:       r.recorder,
 	}
 
+	// Status is computed in memory during reconciliation and written once at the end
+	originalStatus := instance.Status.DeepCopy()
+	defer func() {
+		setUpgradingCondition(tektonRequest.Instance)
+		reportDegraded(tektonRequest.Instance)
+		statusErr := writeStatus(tektonRequest, originalStatus)
+		if statusErr != nil {
+			reqLogger.Error(statusErr, "Error updating Tekton tasks status.")
+			if err == nil {
+				err = statusErr
+			}
+		}
+		r.reportUpgradeable(tektonRequest)
+		r.readiness.ReconcileCompleted(err)
+	}()
+
 	if !isInitialized(tektonRequest.Instance) {
 		err := initialize(tektonRequest)
 		return handleError(tektonRequest, err)
 	}
 
-	if updated, err := updateTektonTasks(tektonRequest); updated || (err != nil) {
-		// tekton was updated, and the update will trigger reconciliation again.
-		return handleError(tektonRequest, err)
-	}
-
 	if isBeingDeleted(tektonRequest.Instance) {
 		err := r.cleanup(tektonRequest)
 		if err != nil {
@@ -135,7 +180,7 @@ func (r *tektonTasksReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if len(tektonTaskList.Items) > 1 {
-		return ctrl.Result{}, r.setStatusMultipleCRs(ctx, tektonTaskList)
+		return ctrl.Result{}, r.setStatusMultipleCRs(tektonRequest, tektonTaskList)
 	}
 
 	if isPaused(instance) {
@@ -145,43 +190,154 @@ func (r *tektonTasksReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		reqLogger.Info(fmt.Sprintf("Pausing Tekton operator on resource: %v/%v", instance.Namespace, instance.Name))
 		instance.Status.Paused = true
 		instance.Status.ObservedGeneration = instance.Generation
-		err := r.client.Status().Update(ctx, instance)
-		return ctrl.Result{}, err
+		conditionsv1.SetStatusCondition(&instance.Status.Conditions, conditionsv1.Condition{
+			Type:    conditionsv1.ConditionProgressing,
+			Status:  v1.ConditionFalse,
+			Reason:  tekton.ReasonPaused,
+			Message: fmt.Sprintf("Reconciliation is paused by the %s annotation", tekton.OperatorPausedAnnotation),
+		})
+		common.RecordEvent(tektonRequest, v1.EventTypeNormal, common.EventReasonPaused,
+			fmt.Sprintf("Reconciliation paused by the %s annotation", tekton.OperatorPausedAnnotation))
+		return ctrl.Result{}, nil
 	}
 
-	tektonRequest.Logger.V(1).Info("Updating CR status prior to operand reconciliation...")
-	err = preUpdateStatus(tektonRequest)
+	// Migrations run before operands, so operands reconcile resources in the format of the current version
+	err = r.migrations.Run(tektonRequest)
 	if err != nil {
 		return handleError(tektonRequest, err)
 	}
-	tektonRequest.Logger.V(1).Info("CR status updated")
+
+	preUpdateStatus(tektonRequest)
 
 	reconcileResults := []common.ReconcileResult{}
 	if tektonRequest.Instance.Spec.FeatureGates.DeployTektonTaskResources {
+		tektonRequest.APIGroups, err = common.ServedAPIGroups(r.discovery)
+		if err != nil {
+			return handleError(tektonRequest, err)
+		}
+		tektonRequest.TopologyMode, err = common.GetTopologyMode(tektonRequest)
+		if err != nil {
+			return handleError(tektonRequest, err)
+		}
+		updateWindows, err := common.CheckUpdateWindows(tektonRequest.Instance.Spec.UpdateWindows, time.Now())
+		if err != nil {
+			return handleError(tektonRequest, err)
+		}
+		tektonRequest.UpdateWindowClosed = !updateWindows.Open
+		tektonRequest.Instance.Status.NextUpdateWindow = nil
+		if updateWindows.NextStart != nil {
+			tektonRequest.Logger.V(1).Info("No update window is open, existing resources are not updated", "nextUpdateWindow", updateWindows.NextStart)
+			nextStart := metav1.NewTime(*updateWindows.NextStart)
+			tektonRequest.Instance.Status.NextUpdateWindow = &nextStart
+		}
+		tektonRequest.Capabilities = common.DetectCapabilities(tektonRequest.APIGroups, tektonRequest.Instance.Spec.Platform)
+		bundleVariant := string(tektonbundle.SelectVariant(tektonRequest.Capabilities))
+		previousVariant := tektonRequest.Instance.Status.BundleVariant
+		tektonRequest.BundleVariantChanged = previousVariant != "" && previousVariant != bundleVariant
 		tektonRequest.Logger.Info("Reconciling operands...")
 		reconcileResults, err = r.reconcileOperands(tektonRequest)
 		if err != nil {
 			return handleError(tektonRequest, err)
 		}
+		// The variant is stored only after a successful reconcile, so resources of the previous variant are pruned again on failure
+		tektonRequest.Instance.Status.Capabilities = tektonRequest.Capabilities.Names()
+		tektonRequest.Instance.Status.BundleVariant = bundleVariant
+		tektonRequest.Instance.Status.TopologyProfile = common.TopologyProfile(tektonRequest)
 		tektonRequest.Logger.V(1).Info("Operands reconciled")
 	} else {
 		tektonRequest.Logger.V(1).Info("Resources were not deployed, because spec.featureGates.deployTektonTaskResources is set to false")
+		for _, operand := range r.operands {
+			conditionsv1.SetStatusCondition(&tektonRequest.Instance.Status.Conditions, conditionsv1.Condition{
+				Type:    operand.ReadyConditionType(),
+				Status:  v1.ConditionFalse,
+				Reason:  tekton.ReasonDeploymentDisabled,
+				Message: "Resources are not deployed, because spec.featureGates.deployTektonTaskResources is set to false",
+			})
+		}
 	}
 
-	tektonRequest.Logger.V(1).Info("Updating CR status post reconciliation...")
-	err = updateStatus(tektonRequest, reconcileResults)
-	if err != nil {
-		return handleError(tektonRequest, err)
+	updateStatus(tektonRequest, reconcileResults)
+
+	if tektonRequest.Instance.Status.Phase != lifecycleapi.PhaseDeployed {
+		if onlyUpdatesDeferred(reconcileResults) {
+			// Updates deferred by the update strategy are expected, so they do not fire the alert
+			common.TektonOperatorReconcilingProperly.Set(1)
+		} else {
+			common.TektonOperatorReconcilingProperly.Set(0)
+		}
+		// Some resources are not ready yet. The request is requeued by the rate limiter
+		// with exponential backoff, which is reset once all resources are ready.
+		return ctrl.Result{Requeue: true}, nil
 	}
-	tektonRequest.Logger.Info("CR status updated")
 
-	if tektonRequest.Instance.Status.Phase == lifecycleapi.PhaseDeployed {
-		common.TektonOperatorReconcilingProperly.Set(1)
-	} else {
-		common.TektonOperatorReconcilingProperly.Set(0)
+	common.TektonOperatorReconcilingProperly.Set(1)
+	if nextWindow := tektonRequest.Instance.Status.NextUpdateWindow; nextWindow != nil && hasDeferredUpdates(reconcileResults) {
+		// Only updates waiting for an update window remain, so there is nothing to retry until it opens
+		return r.resyncResultBefore(time.Until(nextWindow.Time)), nil
+	}
+	return r.resyncResult(), nil
+}
+
+// hasDeferredUpdates returns true, if updates of some resources are deferred.
+func hasDeferredUpdates(reconcileResults []common.ReconcileResult) bool {
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult == common.OperationResultDeferred {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyDeferredUntilUpdateWindow returns true, if all results are updates deferred until the next update window.
+func onlyDeferredUntilUpdateWindow(reconcileResults []common.ReconcileResult) bool {
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult != common.OperationResultDeferred ||
+			reconcileResult.DeferReason != tekton.PendingUpdateReasonOutsideUpdateWindow {
+			return false
+		}
 	}
+	return len(reconcileResults) > 0
+}
 
-	return ctrl.Result{}, nil
+// onlyUpdatesDeferred returns true, if some resources are not ready and all of them have deferred updates.
+func onlyUpdatesDeferred(reconcileResults []common.ReconcileResult) bool {
+	deferred := false
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.IsSuccess() {
+			continue
+		}
+		if reconcileResult.OperationResult != common.OperationResultDeferred {
+			return false
+		}
+		deferred = true
+	}
+	return deferred
+}
+
+// resyncResult schedules periodic reconciliation, so changes of fields
+// that do not trigger watch events are eventually reverted.
+func (r *tektonTasksReconciler) resyncResult() ctrl.Result {
+	return ctrl.Result{RequeueAfter: environment.GetResyncPeriod()}
+}
+
+// resyncResultBefore schedules reconciliation after the delay, or after the resync period, if it is shorter.
+func (r *tektonTasksReconciler) resyncResultBefore(delay time.Duration) ctrl.Result {
+	if resync := environment.GetResyncPeriod(); resync > 0 && resync < delay {
+		delay = resync
+	}
+	if delay <= 0 {
+		return ctrl.Result{Requeue: true}
+	}
+	return ctrl.Result{RequeueAfter: delay}
+}
+
+// rateLimiter limits requeues of failed or not ready reconciliations.
+// It is the controller-runtime default rate limiter with configurable per-item delays.
+func rateLimiter() ratelimiter.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(environment.GetRequeueBaseDelay(), environment.GetRequeueMaxDelay()),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
 }
 
 func (r *tektonTasksReconciler) getCRList(ctx context.Context) (*tekton.TektonTasksList, error) {
@@ -195,64 +351,112 @@ func (r *tektonTasksReconciler) getCRList(ctx context.Context) (*tekton.TektonTa
 	return tektonTasksCRList, nil
 }
 
-func (r *tektonTasksReconciler) setStatusMultipleCRs(ctx context.Context, tektonTasksList *tekton.TektonTasksList) error {
+// setStatusMultipleCRs marks all TektonTasks CRs as degraded. The status of the reconciled CR is only set in memory,
+// because it is written at the end of the reconcile. Other CRs are patched directly.
+func (r *tektonTasksReconciler) setStatusMultipleCRs(request *common.Request, tektonTasksList *tekton.TektonTasksList) error {
 	const errMsg = "there are multiple CRs deployed"
 	r.log.Error(nil, errMsg)
 
-	for _, tektonTask := range tektonTasksList.Items {
-		conditionsv1.SetStatusCondition(&tektonTask.Status.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionAvailable,
-			Status:  v1.ConditionFalse,
-			Reason:  "Available",
-			Message: errMsg,
-		})
-		conditionsv1.SetStatusCondition(&tektonTask.Status.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionProgressing,
-			Status:  v1.ConditionFalse,
-			Reason:  "Progressing",
-			Message: errMsg,
-		})
-		conditionsv1.SetStatusCondition(&tektonTask.Status.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionDegraded,
-			Status:  v1.ConditionTrue,
-			Reason:  "Degraded",
-			Message: errMsg,
-		})
-
-		err := r.client.Status().Update(ctx, &tektonTask)
-		if err != nil {
-			return err
+	eventMsg := fmt.Sprintf("There are %d TektonTasks CRs deployed, only one is supported", len(tektonTasksList.Items))
+	for i := range tektonTasksList.Items {
+		tektonTask := &tektonTasksList.Items[i]
+		if tektonTask.UID == request.Instance.UID {
+			setMainConditions(&request.Instance.Status.Conditions, v1.ConditionFalse, v1.ConditionFalse, v1.ConditionTrue,
+				tekton.ReasonMultipleCRs, errMsg)
+		} else {
+			originalStatus := tektonTask.Status.DeepCopy()
+			setMainConditions(&tektonTask.Status.Conditions, v1.ConditionFalse, v1.ConditionFalse, v1.ConditionTrue,
+				tekton.ReasonMultipleCRs, errMsg)
+			err := patchStatus(request.Context, r.client, tektonTask, originalStatus)
+			if err != nil {
+				return err
+			}
+		}
+		if r.recorder != nil {
+			r.recorder.Event(tektonTask, v1.EventTypeWarning, common.EventReasonMultipleCRs, eventMsg)
 		}
 	}
 	return nil
 }
 
 func (r *tektonTasksReconciler) reconcileOperands(tektonRequest *common.Request) ([]common.ReconcileResult, error) {
-	// Reconcile all operands
+	// Reconcile all operands, operands are sorted by their dependencies
 	allReconcileResults := make([]common.ReconcileResult, 0, len(r.operands))
+	failedOperands := make(map[string]bool, len(r.operands))
 	for _, operand := range r.operands {
+		if dependency, failed := common.FailedDependency(operand.Dependencies(), failedOperands); failed {
+			message := fmt.Sprintf("Operand %s was skipped, because prerequisite operand %s failed", operand.Name(), dependency)
+			tektonRequest.Logger.Info(message)
+			failedOperands[operand.Name()] = true
+			skippedResults := []common.ReconcileResult{common.SkippedResult(operandResource(tektonRequest), message)}
+			setOperandCondition(tektonRequest, operand, skippedResults)
+			allReconcileResults = append(allReconcileResults, skippedResults...)
+			continue
+		}
+
 		tektonRequest.Logger.V(1).Info(fmt.Sprintf("Reconciling operand: %s", operand.Name()))
+		tektonRequest.VersionCache = r.operandCache(operand.Name(), tektonRequest.Instance)
+		start := time.Now()
+		_, endSpan := common.StartSpan(tektonRequest, "ReconcileOperand", common.OperandAttribute.String(operand.Name()))
 		reconcileResults, err := operand.Reconcile(tektonRequest)
+		endSpan(err)
+		common.TektonOperatorReconcileDuration.WithLabelValues(operand.Name()).Observe(time.Since(start).Seconds())
 		if err != nil {
 			tektonRequest.Logger.Info(fmt.Sprintf("Operand reconciliation failed: %s", err.Error()))
 			return nil, err
 		}
+		if common.AnyFailed(reconcileResults) {
+			failedOperands[operand.Name()] = true
+		}
+		setOperandCondition(tektonRequest, operand, reconcileResults)
 		allReconcileResults = append(allReconcileResults, reconcileResults...)
 	}
 
 	return allReconcileResults, nil
 }
 
-func (r *tektonTasksReconciler) setupController(mgr ctrl.Manager) error {
-	builder := ctrl.NewControllerManagedBy(mgr)
+// operandResource returns the TektonTasks CR, that is reported as the resource of skipped operands.
+func operandResource(request *common.Request) client.Object {
+	resource := request.Instance.DeepCopy()
+	resource.SetGroupVersionKind(tekton.GroupVersion.WithKind("TektonTasks"))
+	return resource
+}
+
+func (r *tektonTasksReconciler) setupController(mgr ctrl.Manager, requeue <-chan event.GenericEvent) error {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: rateLimiter()})
 
 	watchTektonResource(builder)
 
+	// Requests from the CRD controller, e.g. when required CRDs are installed again
+	builder.Watches(&source.Channel{Source: requeue}, &handler.EnqueueRequestForObject{})
+
 	watchClusterResources(builder, r.operands)
 
 	watchNamespacedResources(builder, r.operands)
 
-	return builder.Complete(r)
+	watchOptionalAPIGroups(builder, mgr.GetClient(), r.operands)
+
+	err := builder.Complete(r)
+	if err != nil {
+		return err
+	}
+
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		// The controller starts processing events after the cache is synced
+		if mgr.GetCache().WaitForCacheSync(ctx) {
+			r.readiness.SetControllerStarted(true)
+		}
+		// The controller is stopped together with the manager, when required CRDs are removed
+		<-ctx.Done()
+		r.readiness.SetControllerStarted(false)
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	return setupRunMetricsControllers(mgr)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -262,16 +466,20 @@ func (r *tektonTasksReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *tektonTasksReconciler) clearCacheIfNeeded(tektonObj *tekton.TektonTasks) {
-	if !reflect.DeepEqual(r.lastTektonTasksSpec, tektonObj.Spec) {
-		r.subresourceCache = common.VersionCache{}
-		r.lastTektonTasksSpec = tektonObj.Spec
+// operandCache returns the version cache of the operand. A new cache is restored from the inventory of the instance,
+// so resources deployed before the operator restarted are not updated again.
+func (r *tektonTasksReconciler) operandCache(operandName string, instance *tekton.TektonTasks) common.VersionCache {
+	cache, ok := r.subresourceCaches[operandName]
+	if !ok {
+		cache = common.VersionCache{}
+		cache.Restore(instance.Status.Inventory)
+		r.subresourceCaches[operandName] = cache
 	}
+	return cache
 }
 
 func (r *tektonTasksReconciler) clearCache() {
-	r.lastTektonTasksSpec = tekton.TektonTasksSpec{}
-	r.subresourceCache = common.VersionCache{}
+	r.subresourceCaches = map[string]common.VersionCache{}
 }
 
 func isPaused(object metav1.Object) bool {
@@ -302,33 +510,18 @@ func initialize(request *common.Request) error {
 	return updateTektonTasksResource(request)
 }
 
-func updateTektonTasks(request *common.Request) (bool, error) {
-	updated := false
-
-	// Update old finalizer to new one
-	if controllerutil.ContainsFinalizer(request.Instance, oldFinalizerName) {
-		controllerutil.RemoveFinalizer(request.Instance, oldFinalizerName)
-		controllerutil.AddFinalizer(request.Instance, finalizerName)
-		updated = true
-	}
-
-	if !updated {
-		return false, nil
-	}
-
-	err := updateTektonTasksResource(request)
-	return err == nil, err
-}
-
 func updateTektonTasksResource(request *common.Request) error {
+	// Update overwrites the in-memory status with the one stored in the cluster
+	status := request.Instance.Status.DeepCopy()
 	err := request.Client.Update(request.Context, request.Instance)
 	if err != nil {
 		return err
 	}
 
+	request.Instance.Status = *status
 	request.Instance.Status.Phase = lifecycleapi.PhaseDeploying
 	request.Instance.Status.ObservedGeneration = request.Instance.Generation
-	return request.Client.Status().Update(request.Context, request.Instance)
+	return nil
 }
 
 func (r *tektonTasksReconciler) cleanup(request *common.Request) error {
@@ -337,33 +530,25 @@ func (r *tektonTasksReconciler) cleanup(request *common.Request) error {
 		tektonStatus := &request.Instance.Status
 		tektonStatus.Phase = lifecycleapi.PhaseDeleting
 		tektonStatus.ObservedGeneration = request.Instance.Generation
-		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionAvailable,
-			Status:  v1.ConditionFalse,
-			Reason:  "Available",
-			Message: "Deleting Tekton tasks resources",
-		})
-		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionProgressing,
-			Status:  v1.ConditionTrue,
-			Reason:  "Progressing",
-			Message: "Deleting Tekton tasks resources",
-		})
-		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-			Type:    conditionsv1.ConditionDegraded,
-			Status:  v1.ConditionTrue,
-			Reason:  "Degraded",
-			Message: "Deleting Tekton tasks resources",
-		})
-
-		err := request.Client.Status().Update(request.Context, request.Instance)
-		if err != nil {
-			return err
-		}
+		setMainConditions(&tektonStatus.Conditions, v1.ConditionFalse, v1.ConditionTrue, v1.ConditionTrue,
+			tekton.ReasonDeleting, "Deleting Tekton tasks resources")
+		common.RecordEvent(request, v1.EventTypeNormal, common.EventReasonCleanupStarted, "Deleting Tekton tasks resources")
 
+		// Operands are cleaned up in reverse dependency order. An operand is not cleaned up,
+		// until all operands depending on it are removed.
 		pendingCount := 0
-		for _, operand := range r.operands {
+		pendingOperands := make(map[string]bool, len(r.operands))
+		for i := len(r.operands) - 1; i >= 0; i-- {
+			operand := r.operands[i]
+			if r.hasPendingDependent(operand.Name(), pendingOperands) {
+				pendingOperands[operand.Name()] = true
+				pendingCount += 1
+				continue
+			}
+
+			_, endSpan := common.StartSpan(request, "CleanupOperand", common.OperandAttribute.String(operand.Name()))
 			cleanupResults, err := operand.Cleanup(request)
+			endSpan(err)
 			if err != nil {
 				return err
 			}
@@ -371,6 +556,7 @@ func (r *tektonTasksReconciler) cleanup(request *common.Request) error {
 			for _, result := range cleanupResults {
 				if !result.Deleted {
 					pendingCount += 1
+					pendingOperands[operand.Name()] = true
 				}
 			}
 		}
@@ -382,52 +568,74 @@ func (r *tektonTasksReconciler) cleanup(request *common.Request) error {
 
 		controllerutil.RemoveFinalizer(request.Instance, finalizerName)
 		controllerutil.RemoveFinalizer(request.Instance, oldFinalizerName)
-		err = request.Client.Update(request.Context, request.Instance)
+		err := updateTektonTasksResource(request)
 		if err != nil {
 			return err
 		}
+		common.RecordEvent(request, v1.EventTypeNormal, common.EventReasonCleanupFinished, "All Tekton tasks resources were deleted")
 	}
 
 	request.Instance.Status.Phase = lifecycleapi.PhaseDeleted
 	request.Instance.Status.ObservedGeneration = request.Instance.Generation
-	err := request.Client.Status().Update(request.Context, request.Instance)
-	if errors.IsConflict(err) || errors.IsNotFound(err) {
-		// These errors are ignored. They can happen if the CR was removed
-		// before the status update call is executed.
-		return nil
-	}
-	return err
+	return nil
 }
 
-func pauseCRs(tektonRequest *common.Request, kinds []string) error {
-	patch := []byte(`{
-  "metadata":{
-    "annotations":{"kubevirt.io/operator.paused": "true"}
-  }
-}`)
-	for _, kind := range kinds {
-		crs := &unstructured.UnstructuredList{}
-		crs.SetKind(kind)
-		crs.SetAPIVersion("tektontasks.kubevirt.io/v1")
-		err := tektonRequest.Client.List(tektonRequest.Context, crs)
-		if err != nil {
-			tektonRequest.Logger.Error(err, fmt.Sprintf("Error listing %s CRs: %s", kind, err))
-			return err
+func (r *tektonTasksReconciler) hasPendingDependent(name string, pendingOperands map[string]bool) bool {
+	for _, operand := range r.operands {
+		if !pendingOperands[operand.Name()] {
+			continue
 		}
-		for _, item := range crs.Items {
-			err = tektonRequest.Client.Patch(tektonRequest.Context, &item, client.RawPatch(types.MergePatchType, patch))
-			if err != nil {
-				// Patching failed, maybe the CR just got removed? Log an error but keep going.
-				tektonRequest.Logger.Error(err, fmt.Sprintf("Error pausing %s from namespace %s: %s",
-					item.GetName(), item.GetNamespace(), err))
+		for _, dependency := range operand.Dependencies() {
+			if dependency == name {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	return nil
+// reportUpgradeable reports to OLM, whether the operator can be upgraded. It is called once per reconcile
+// with the final status. Failures are only logged, because the condition is reported again by the next reconcile.
+func (r *tektonTasksReconciler) reportUpgradeable(request *common.Request) {
+	err := r.upgradeable.Report(request.Context, request.Instance)
+	if err != nil {
+		request.Logger.Error(err, "Failed to set the Upgradeable operator condition")
+	}
 }
 
-func preUpdateStatus(request *common.Request) error {
+// setUpgradingCondition reports, whether resources deployed by a previous version of the operator are being upgraded.
+func setUpgradingCondition(instance *tekton.TektonTasks) {
+	tektonStatus := &instance.Status
+	if tektonStatus.ObservedVersion == "" || tektonStatus.TargetVersion == "" ||
+		tektonStatus.ObservedVersion == tektonStatus.TargetVersion {
+		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
+			Type:    tekton.ConditionUpgrading,
+			Status:  v1.ConditionFalse,
+			Reason:  tekton.ReasonAsExpected,
+			Message: "Resources are not being upgraded",
+		})
+		return
+	}
+	if tektonStatus.Phase == lifecycleapi.PhaseDeployed && tektonStatus.NextUpdateWindow != nil {
+		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
+			Type:   tekton.ConditionUpgrading,
+			Status: v1.ConditionTrue,
+			Reason: tekton.ReasonOutsideUpdateWindow,
+			Message: fmt.Sprintf("Upgrade of resources from version %s to %s continues in the update window at %s",
+				tektonStatus.ObservedVersion, tektonStatus.TargetVersion, tektonStatus.NextUpdateWindow.UTC().Format(time.RFC3339)),
+		})
+		return
+	}
+	conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
+		Type:   tekton.ConditionUpgrading,
+		Status: v1.ConditionTrue,
+		Reason: tekton.ReasonUpgrading,
+		Message: fmt.Sprintf("Upgrading resources from version %s to %s",
+			tektonStatus.ObservedVersion, tektonStatus.TargetVersion),
+	})
+}
+
+func preUpdateStatus(request *common.Request) {
 	operatorVersion := environment.GetOperatorVersion()
 
 	tektonStatus := &request.Instance.Status
@@ -439,6 +647,7 @@ func preUpdateStatus(request *common.Request) error {
 	if tektonStatus.Paused {
 		request.Logger.Info(fmt.Sprintf("Unpausing Tekton tasks operator on resource: %v/%v",
 			request.Instance.Namespace, request.Instance.Name))
+		common.RecordEvent(request, v1.EventTypeNormal, common.EventReasonResumed, "Reconciliation resumed")
 	}
 	tektonStatus.Paused = false
 
@@ -446,7 +655,7 @@ func preUpdateStatus(request *common.Request) error {
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionFalse,
-			Reason:  "Available",
+			Reason:  tekton.ReasonReconciling,
 			Message: "Reconciling Tekton tasks resources",
 		})
 	}
@@ -455,7 +664,7 @@ func preUpdateStatus(request *common.Request) error {
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionTrue,
-			Reason:  "Progressing",
+			Reason:  tekton.ReasonReconciling,
 			Message: "Reconciling Tekton tasks resources",
 		})
 	}
@@ -464,15 +673,35 @@ func preUpdateStatus(request *common.Request) error {
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionTrue,
-			Reason:  "Degraded",
+			Reason:  tekton.ReasonReconciling,
 			Message: "Reconciling Tekton tasks resources",
 		})
 	}
+}
 
-	return request.Client.Status().Update(request.Context, request.Instance)
+// setMainConditions sets the Available, Progressing and Degraded conditions with a common reason and message.
+func setMainConditions(conditions *[]conditionsv1.Condition, available, progressing, degraded v1.ConditionStatus, reason, message string) {
+	conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionAvailable,
+		Status:  available,
+		Reason:  reason,
+		Message: message,
+	})
+	conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionProgressing,
+		Status:  progressing,
+		Reason:  reason,
+		Message: message,
+	})
+	conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionDegraded,
+		Status:  degraded,
+		Reason:  reason,
+		Message: message,
+	})
 }
 
-func updateStatus(request *common.Request, reconcileResults []common.ReconcileResult) error {
+func updateStatus(request *common.Request, reconcileResults []common.ReconcileResult) {
 	notAvailable := make([]common.ReconcileResult, 0, len(reconcileResults))
 	progressing := make([]common.ReconcileResult, 0, len(reconcileResults))
 	degraded := make([]common.ReconcileResult, 0, len(reconcileResults))
@@ -494,7 +723,7 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionTrue,
-			Reason:  "Available",
+			Reason:  tekton.ReasonAsExpected,
 			Message: "Tekton operator is available",
 		})
 	case 1:
@@ -502,14 +731,14 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionFalse,
-			Reason:  "Available",
+			Reason:  resultsReason(notAvailable, tekton.ReasonResourcesNotAvailable),
 			Message: prefixResourceTypeAndName(*reconcileResult.Status.NotAvailable, reconcileResult.Resource),
 		})
 	default:
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionFalse,
-			Reason:  "Available",
+			Reason:  resultsReason(notAvailable, tekton.ReasonResourcesNotAvailable),
 			Message: fmt.Sprintf("%d Tekton tasks resources are not available", len(notAvailable)),
 		})
 	}
@@ -519,7 +748,7 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionFalse,
-			Reason:  "Progressing",
+			Reason:  tekton.ReasonAsExpected,
 			Message: "No Tekton tasks resources are progressing",
 		})
 	case 1:
@@ -527,14 +756,14 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionTrue,
-			Reason:  "Progressing",
+			Reason:  resultsReason(progressing, tekton.ReasonResourcesProgressing),
 			Message: prefixResourceTypeAndName(*reconcileResult.Status.Progressing, reconcileResult.Resource),
 		})
 	default:
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionTrue,
-			Reason:  "Progressing",
+			Reason:  resultsReason(progressing, tekton.ReasonResourcesProgressing),
 			Message: fmt.Sprintf("%d Tekton tasks resources are progressing", len(progressing)),
 		})
 	}
@@ -544,7 +773,7 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionFalse,
-			Reason:  "Degraded",
+			Reason:  tekton.ReasonAsExpected,
 			Message: "No Tekton tasks resources are degraded",
 		})
 	case 1:
@@ -552,27 +781,315 @@ func updateStatus(request *common.Request, reconcileResults []common.ReconcileRe
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionTrue,
-			Reason:  "Degraded",
+			Reason:  resultsReason(degraded, tekton.ReasonResourcesDegraded),
 			Message: prefixResourceTypeAndName(*reconcileResult.Status.Degraded, reconcileResult.Resource),
 		})
 	default:
 		conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionTrue,
-			Reason:  "Degraded",
+			Reason:  resultsReason(degraded, tekton.ReasonResourcesDegraded),
 			Message: fmt.Sprintf("%d Tekton tasks resources are degraded", len(degraded)),
 		})
 	}
 
+	tektonStatus.Conflicts = collectConflicts(request, reconcileResults)
+	tektonStatus.Inventory = collectInventory(reconcileResults)
+	tektonStatus.Skipped = collectSkipped(reconcileResults)
+	tektonStatus.PendingUpdates = collectPendingUpdates(tektonStatus.PendingUpdates, reconcileResults)
+	reportManagedResources(tektonStatus.Inventory)
+	if lastPrune := collectPruned(reconcileResults); lastPrune != nil {
+		tektonStatus.LastPrune = lastPrune
+	}
+
 	tektonStatus.ObservedGeneration = request.Instance.Generation
 	if len(notAvailable) == 0 && len(progressing) == 0 && len(degraded) == 0 {
 		tektonStatus.Phase = lifecycleapi.PhaseDeployed
 		tektonStatus.ObservedVersion = environment.GetOperatorVersion()
+	} else if len(notAvailable) == 0 && len(degraded) == 0 && onlyDeferredUntilUpdateWindow(progressing) {
+		// Resources are deployed and the remaining updates are scheduled, the version is observed after they are applied
+		tektonStatus.Phase = lifecycleapi.PhaseDeployed
 	} else {
 		tektonStatus.Phase = lifecycleapi.PhaseDeploying
 	}
+}
+
+// writeStatus patches the status of the TektonTasks CR, if it differs from the original status.
+func writeStatus(request *common.Request, originalStatus *tekton.TektonTasksStatus) error {
+	err := patchStatus(request.Context, request.Client, request.Instance, originalStatus)
+	if errors.IsNotFound(err) {
+		// The CR was removed before the status could be written
+		return nil
+	}
+	return err
+}
+
+func patchStatus(ctx context.Context, cli client.Client, instance *tekton.TektonTasks, originalStatus *tekton.TektonTasksStatus) error {
+	if !statusChanged(originalStatus, &instance.Status) {
+		return nil
+	}
+	// The patch only contains the status, so it does not conflict with metadata changes
+	base := instance.DeepCopy()
+	base.Status = *originalStatus
+	return cli.Status().Patch(ctx, instance, client.MergeFrom(base))
+}
+
+// statusChanged compares statuses ignoring condition heartbeat times,
+// which are updated on every reconciliation.
+func statusChanged(original, updated *tekton.TektonTasksStatus) bool {
+	return !equality.Semantic.DeepEqual(withoutHeartbeats(original), withoutHeartbeats(updated))
+}
+
+func withoutHeartbeats(status *tekton.TektonTasksStatus) *tekton.TektonTasksStatus {
+	status = status.DeepCopy()
+	for i := range status.Conditions {
+		status.Conditions[i].LastHeartbeatTime = metav1.Time{}
+	}
+	return status
+}
+
+// maxListedResources limits the number of resources listed in a condition message
+const maxListedResources = 10
+
+// setOperandCondition sets the ready condition of an operand, listing its resources, that are not ready.
+func setOperandCondition(request *common.Request, operand operands.Operand, reconcileResults []common.ReconcileResult) {
+	var failed []common.ReconcileResult
+	for _, reconcileResult := range reconcileResults {
+		if !reconcileResult.IsSuccess() {
+			failed = append(failed, reconcileResult)
+		}
+	}
+
+	if len(failed) == 0 {
+		conditionsv1.SetStatusCondition(&request.Instance.Status.Conditions, conditionsv1.Condition{
+			Type:    operand.ReadyConditionType(),
+			Status:  v1.ConditionTrue,
+			Reason:  tekton.ReasonAsExpected,
+			Message: fmt.Sprintf("All %d resources are ready", len(reconcileResults)),
+		})
+		return
+	}
+
+	names := make([]string, 0, maxListedResources+1)
+	for i, reconcileResult := range failed {
+		if i == maxListedResources {
+			names = append(names, fmt.Sprintf("and %d more", len(failed)-maxListedResources))
+			break
+		}
+		names = append(names, fmt.Sprintf("%s %s",
+			reconcileResult.Resource.GetObjectKind().GroupVersionKind().Kind,
+			client.ObjectKeyFromObject(reconcileResult.Resource)))
+	}
+	conditionsv1.SetStatusCondition(&request.Instance.Status.Conditions, conditionsv1.Condition{
+		Type:    operand.ReadyConditionType(),
+		Status:  v1.ConditionFalse,
+		Reason:  resultsReason(failed, tekton.ReasonResourcesNotReady),
+		Message: fmt.Sprintf("%d resources are not ready: %s", len(failed), strings.Join(names, ", ")),
+	})
+}
+
+// resultsReason returns a specific reason if it is shared by all results, otherwise the default reason.
+func resultsReason(reconcileResults []common.ReconcileResult, defaultReason string) string {
+	reason := ""
+	for i, reconcileResult := range reconcileResults {
+		var resultReason string
+		switch reconcileResult.OperationResult {
+		case common.OperationResultConflict:
+			resultReason = tekton.ReasonResourceConflict
+		case common.OperationResultSkipped:
+			resultReason = tekton.ReasonDependencyFailed
+		case common.OperationResultDeferred:
+			resultReason = tekton.ReasonUpdateDeferred
+			if reconcileResult.DeferReason == tekton.PendingUpdateReasonOutsideUpdateWindow {
+				resultReason = tekton.ReasonOutsideUpdateWindow
+			}
+		}
+		if resultReason == "" || (i > 0 && resultReason != reason) {
+			return defaultReason
+		}
+		reason = resultReason
+	}
+	if reason == "" {
+		return defaultReason
+	}
+	return reason
+}
 
-	return request.Client.Status().Update(request.Context, request.Instance)
+// collectSkipped returns resources, that are not deployed, because API groups they depend on are not available.
+func collectSkipped(reconcileResults []common.ReconcileResult) []tekton.SkippedResource {
+	var skipped []tekton.SkippedResource
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult != common.OperationResultMissingAPIGroups {
+			continue
+		}
+		skipped = append(skipped, tekton.SkippedResource{
+			ResourceReference: resourceReference(reconcileResult.Resource),
+			Reason:            fmt.Sprintf("Required API groups are not available: %s", strings.Join(reconcileResult.MissingAPIGroups, ", ")),
+			MissingAPIGroups:  reconcileResult.MissingAPIGroups,
+		})
+	}
+	return skipped
+}
+
+// maxListedRuns limits the number of runs listed in a pending update
+const maxListedRuns = 10
+
+// collectPendingUpdates returns resources, whose updates are deferred.
+// Resources, that were deferred before for the same reason, keep the time of the first deferral.
+func collectPendingUpdates(previous []tekton.PendingUpdate, reconcileResults []common.ReconcileResult) []tekton.PendingUpdate {
+	var pendingUpdates []tekton.PendingUpdate
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult != common.OperationResultDeferred {
+			continue
+		}
+		since := metav1.Now()
+		if pending := common.FindPendingUpdate(previous, reconcileResult.Resource, reconcileResult.DeferReason); pending != nil {
+			since = pending.Since
+		}
+		runs := reconcileResult.Runs
+		if len(runs) > maxListedRuns {
+			runs = runs[:maxListedRuns]
+		}
+		pendingUpdates = append(pendingUpdates, tekton.PendingUpdate{
+			ResourceReference: resourceReference(reconcileResult.Resource),
+			Reason:            reconcileResult.DeferReason,
+			Since:             since,
+			Runs:              runs,
+			RunCount:          len(reconcileResult.Runs),
+		})
+	}
+	return pendingUpdates
+}
+
+func collectConflicts(request *common.Request, reconcileResults []common.ReconcileResult) []tekton.ResourceConflict {
+	var conflicts []tekton.ResourceConflict
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult != common.OperationResultConflict {
+			continue
+		}
+		conflicts = append(conflicts, tekton.ResourceConflict{
+			ResourceReference: resourceReference(reconcileResult.Resource),
+			Policy:            common.AdoptionPolicy(request),
+		})
+	}
+	return conflicts
+}
+
+// collectInventory returns resources deployed by the operator, sorted by kind, namespace and name.
+// Pruned resources and skipped operands are not included.
+func collectInventory(reconcileResults []common.ReconcileResult) []tekton.InventoryEntry {
+	var inventory []tekton.InventoryEntry
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult == common.OperationResultPruned ||
+			reconcileResult.OperationResult == common.OperationResultMissingAPIGroups {
+			continue
+		}
+		if _, isOperand := reconcileResult.Resource.(*tekton.TektonTasks); isOperand {
+			continue
+		}
+
+		health, message := resourceHealth(reconcileResult.Status)
+		entry := tekton.InventoryEntry{
+			ResourceReference: resourceReference(reconcileResult.Resource),
+			Version:           resourceVersion(reconcileResult.Resource),
+			Images:            resourceImages(reconcileResult.Resource),
+			OperationResult:   string(reconcileResult.OperationResult),
+			Health:            health,
+			Message:           message,
+		}
+		if found := reconcileResult.Found; found != nil {
+			entry.UID = found.GetUID()
+			entry.ResourceVersion = found.GetResourceVersion()
+			entry.Generation = found.GetGeneration()
+		}
+		inventory = append(inventory, entry)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return inventory
+}
+
+// resourceVersion returns the tasks version of ClusterTasks, or the app version label of other resources.
+func resourceVersion(resource client.Object) string {
+	if version, ok := resource.GetLabels()[tektontasks.TektonTasksVersionLabel]; ok {
+		return version
+	}
+	return resource.GetLabels()[common.AppKubernetesVersionLabel]
+}
+
+func resourceHealth(status common.ResourceStatus) (tekton.ResourceHealth, string) {
+	switch {
+	case status.Degraded != nil:
+		return tekton.ResourceHealthDegraded, *status.Degraded
+	case status.NotAvailable != nil:
+		return tekton.ResourceHealthNotAvailable, *status.NotAvailable
+	case status.Progressing != nil:
+		return tekton.ResourceHealthProgressing, *status.Progressing
+	default:
+		return tekton.ResourceHealthHealthy, ""
+	}
+}
+
+// resourceImages returns container images used by the resource, without duplicates.
+func resourceImages(resource client.Object) []string {
+	clusterTask, ok := resource.(*pipeline.ClusterTask)
+	if !ok {
+		return nil
+	}
+
+	var images []string
+	seen := map[string]bool{}
+	addImage := func(image string) {
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	if clusterTask.Spec.StepTemplate != nil {
+		addImage(clusterTask.Spec.StepTemplate.Image)
+	}
+	for _, step := range clusterTask.Spec.Steps {
+		addImage(step.Image)
+	}
+	for _, sidecar := range clusterTask.Spec.Sidecars {
+		addImage(sidecar.Image)
+	}
+	return images
+}
+
+// collectPruned returns a summary of pruned resources, or nil if no resource was pruned
+func collectPruned(reconcileResults []common.ReconcileResult) *tekton.PruneSummary {
+	var pruned []tekton.ResourceReference
+	for _, reconcileResult := range reconcileResults {
+		if reconcileResult.OperationResult == common.OperationResultPruned {
+			pruned = append(pruned, resourceReference(reconcileResult.Resource))
+		}
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+	return &tekton.PruneSummary{
+		Time:            metav1.Now(),
+		OperatorVersion: environment.GetOperatorVersion(),
+		Resources:       pruned,
+	}
+}
+
+func resourceReference(resource client.Object) tekton.ResourceReference {
+	return tekton.ResourceReference{
+		Kind:      common.ObjectKind(resource),
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}
 }
 
 func prefixResourceTypeAndName(message string, resource client.Object) string {
@@ -596,31 +1113,11 @@ func handleError(request *common.Request, errParam error) (ctrl.Result, error) {
 
 	// Default error handling, if error is not known
 	errorMsg := fmt.Sprintf("Error: %v", errParam)
+	common.RecordEvent(request, v1.EventTypeWarning, common.EventReasonReconcileFailed, errorMsg)
 	tektonStatus := &request.Instance.Status
 	tektonStatus.Phase = lifecycleapi.PhaseDeploying
-	conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-		Type:    conditionsv1.ConditionAvailable,
-		Status:  v1.ConditionFalse,
-		Reason:  "Available",
-		Message: errorMsg,
-	})
-	conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-		Type:    conditionsv1.ConditionProgressing,
-		Status:  v1.ConditionTrue,
-		Reason:  "Progressing",
-		Message: errorMsg,
-	})
-	conditionsv1.SetStatusCondition(&tektonStatus.Conditions, conditionsv1.Condition{
-		Type:    conditionsv1.ConditionDegraded,
-		Status:  v1.ConditionTrue,
-		Reason:  "Degraded",
-		Message: errorMsg,
-	})
-	err := request.Client.Status().Update(request.Context, request.Instance)
-	if err != nil {
-		request.Logger.Error(err, "Error updating Tekton tasks status.")
-	}
-
+	setMainConditions(&tektonStatus.Conditions, v1.ConditionFalse, v1.ConditionTrue, v1.ConditionTrue,
+		tekton.ReasonReconcileFailed, errorMsg)
 	return ctrl.Result{}, errParam
 }
 
@@ -647,6 +1144,38 @@ func watchTektonResource(bldr *ctrl.Builder) {
 	bldr.For(&tekton.TektonTasks{}, builder.WithPredicates(pred))
 }
 
+// watchOptionalAPIGroups reconciles the TektonTasks CR, when CRDs of API groups, that operands optionally depend on,
+// are installed or removed. Aggregated API groups without CRDs are detected by the periodic reconciliation.
+func watchOptionalAPIGroups(bldr *ctrl.Builder, cli client.Client, tektonOperands []operands.Operand) {
+	groups := make(map[string]bool)
+	for _, operand := range tektonOperands {
+		for _, group := range operand.OptionalAPIGroups() {
+			groups[group] = true
+		}
+	}
+
+	isOptionalGroup := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		crd, ok := obj.(*extv1.CustomResourceDefinition)
+		return ok && groups[crd.Spec.Group]
+	})
+
+	enqueueTektonTasks := handler.EnqueueRequestsFromMapFunc(func(_ client.Object) []reconcile.Request {
+		instances := &tekton.TektonTasksList{}
+		err := cli.List(context.TODO(), instances, client.InNamespace(environment.GetOperatorNamespace()))
+		if err != nil {
+			ctrl.Log.WithName("controllers").Error(err, "Failed to list TektonTasks CRs")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(instances.Items))
+		for i := range instances.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&instances.Items[i])})
+		}
+		return requests
+	})
+
+	bldr.Watches(&source.Kind{Type: &extv1.CustomResourceDefinition{}}, enqueueTektonTasks, builder.WithPredicates(isOptionalGroup))
+}
+
 func watchNamespacedResources(builder *ctrl.Builder, tektonOperands []operands.Operand) {
 	watchResources(builder,
 		&handler.EnqueueRequestForOwner{
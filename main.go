@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 
@@ -26,6 +27,7 @@ import (
 
 	"github.com/kubevirt/tekton-tasks-operator/controllers"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -41,16 +43,39 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var otlpEndpoint string
+	var otlpInsecure bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The host:port of an OTLP/HTTP collector, where traces are exported. Tracing is disabled if empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Export traces over HTTP instead of HTTPS.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	shutdownTracing := func(context.Context) error { return nil }
+	if otlpEndpoint != "" {
+		var err error
+		shutdownTracing, err = common.SetupTracing(context.Background(), otlpEndpoint, otlpInsecure, environment.GetOperatorVersion())
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+	}
+
+	// Deferred functions do not run on os.Exit, so buffered traces are flushed explicitly
+	exit := func(code int) {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+		os.Exit(code)
+	}
+
 	options := ctrl.Options{
 		Scheme:                 common.Scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -58,33 +83,30 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "d98bc7b7.kubevirt.io",
+		NewCache:               controllers.NewCache(),
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
+		exit(1)
 	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+		exit(1)
 	}
 
 	//+kubebuilder:scaffold:builder
 	if err = controllers.CreateAndSetupReconciler(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "tekton-tasks")
-		os.Exit(1)
+		exit(1)
 	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
-		os.Exit(1)
+		exit(1)
 	}
+	exit(0)
 }
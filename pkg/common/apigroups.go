@@ -0,0 +1,60 @@
+package common
+
+import (
+	"sort"
+
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	KubeVirtAPIGroup = "kubevirt.io"
+	CDIAPIGroup      = "cdi.kubevirt.io"
+	TemplateAPIGroup = "template.openshift.io"
+)
+
+// APIGroups holds names of API groups served by the cluster.
+// A nil APIGroups means, that the groups were not discovered, and all groups are treated as available.
+type APIGroups map[string]bool
+
+// ServedAPIGroups discovers API groups served by the cluster.
+func ServedAPIGroups(discoveryClient discovery.ServerGroupsInterface) (APIGroups, error) {
+	groupList, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(APIGroups, len(groupList.Groups))
+	for _, group := range groupList.Groups {
+		groups[group.Name] = true
+	}
+	return groups, nil
+}
+
+// Missing returns sorted names of the required groups, that are not served by the cluster.
+func (a APIGroups) Missing(required []string) []string {
+	if a == nil {
+		return nil
+	}
+
+	var missing []string
+	reported := make(map[string]bool, len(required))
+	for _, group := range required {
+		if !a[group] && !reported[group] {
+			missing = append(missing, group)
+			reported[group] = true
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// MissingAPIGroupsResult returns a result for a resource, that is not deployed,
+// because API groups it depends on are not available. It is not reported as a failure.
+func MissingAPIGroupsResult(resource client.Object, missingGroups []string) ReconcileResult {
+	return ReconcileResult{
+		Resource:         resource,
+		OperationResult:  OperationResultMissingAPIGroups,
+		MissingAPIGroups: missingGroups,
+	}
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeServerGroups struct {
+	groups *metav1.APIGroupList
+	err    error
+}
+
+func (f *fakeServerGroups) ServerGroups() (*metav1.APIGroupList, error) {
+	return f.groups, f.err
+}
+
+var _ = Describe("API groups", func() {
+	It("should return served API groups", func() {
+		groups, err := ServedAPIGroups(&fakeServerGroups{groups: &metav1.APIGroupList{
+			Groups: []metav1.APIGroup{{Name: KubeVirtAPIGroup}, {Name: CDIAPIGroup}},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(groups).To(Equal(APIGroups{KubeVirtAPIGroup: true, CDIAPIGroup: true}))
+	})
+
+	It("should return discovery error", func() {
+		_, err := ServedAPIGroups(&fakeServerGroups{err: errors.New("test error")})
+		Expect(err).To(MatchError("test error"))
+	})
+
+	It("should return sorted missing groups without duplicates", func() {
+		groups := APIGroups{KubeVirtAPIGroup: true}
+		Expect(groups.Missing([]string{TemplateAPIGroup, KubeVirtAPIGroup, CDIAPIGroup, TemplateAPIGroup})).
+			To(Equal([]string{CDIAPIGroup, TemplateAPIGroup}))
+	})
+
+	It("should treat all groups as available when groups were not discovered", func() {
+		var groups APIGroups
+		Expect(groups.Missing([]string{TemplateAPIGroup})).To(BeEmpty())
+	})
+})
@@ -1,10 +1,20 @@
 package common
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 )
 
+// DesiredStateHashAnnotation stores the hash of the desired state, that was last applied to a managed object.
+// It persists change detection across operator restarts.
+const DesiredStateHashAnnotation = "tekton-tasks.kubevirt.io/desired-state-hash"
+
 type cacheKey struct {
 	Kind      string
 	Name      string
@@ -34,9 +44,14 @@ func (v VersionCache) Contains(obj client.Object) bool {
 	return cached.generation == obj.GetGeneration()
 }
 
+// Has returns true if any version of the object is cached.
+func (v VersionCache) Has(obj client.Object) bool {
+	_, ok := v[cacheKeyFromObj(obj)]
+	return ok
+}
+
 func (v VersionCache) Add(obj client.Object) {
-	kind := obj.GetObjectKind().GroupVersionKind().Kind
-	if kind == "" {
+	if ObjectKind(obj) == "" {
 		// Do not cache objects without kind
 		return
 	}
@@ -51,10 +66,56 @@ func (v VersionCache) RemoveObj(obj client.Object) {
 	delete(v, cacheKeyFromObj(obj))
 }
 
+// Restore adds versions of resources recorded in the inventory of the TektonTasks CR,
+// so resources, that were not changed since the last reconcile, are up to date after the operator restarts.
+func (v VersionCache) Restore(inventory []tekton.InventoryEntry) {
+	for _, entry := range inventory {
+		if entry.ResourceVersion == "" {
+			// The version of the resource was not recorded
+			continue
+		}
+		v[cacheKey{Kind: entry.Kind, Name: entry.Name, Namespace: entry.Namespace}] = cacheValue{
+			uid:             entry.UID,
+			resourceVersion: entry.ResourceVersion,
+			generation:      entry.Generation,
+		}
+	}
+}
+
 func cacheKeyFromObj(obj client.Object) cacheKey {
 	return cacheKey{
-		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Kind:      ObjectKind(obj),
 		Name:      obj.GetName(),
 		Namespace: obj.GetNamespace(),
 	}
 }
+
+// DesiredStateHash computes a hash of the object, ignoring the hash annotation itself.
+func DesiredStateHash(obj client.Object) (string, error) {
+	objCopy := obj.DeepCopyObject().(client.Object)
+	annotations := objCopy.GetAnnotations()
+	if annotations != nil {
+		delete(annotations, DesiredStateHashAnnotation)
+		if len(annotations) == 0 {
+			objCopy.SetAnnotations(nil)
+		}
+	}
+
+	objBytes, err := json.Marshal(objCopy)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(objBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// IsUpToDate returns true if the found object does not need to be updated to the desired state with the given hash.
+// Objects are up to date, if the hash annotation, if present, matches and the generation or resource version
+// of the object did not move since it was last reconciled. The cache is restored from the inventory
+// after a restart. Objects not in the cache may have been changed meanwhile, so they are compared with the desired state.
+func (v VersionCache) IsUpToDate(found client.Object, desiredHash string) bool {
+	if foundHash, ok := found.GetAnnotations()[DesiredStateHashAnnotation]; ok && foundHash != desiredHash {
+		return false
+	}
+	return v.Contains(found)
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"sort"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+type Capability string
+
+const (
+	CapabilityTemplates                  Capability = "Templates"
+	CapabilityRoutes                     Capability = "Routes"
+	CapabilitySecurityContextConstraints Capability = "SecurityContextConstraints"
+
+	RouteAPIGroup    = "route.openshift.io"
+	SecurityAPIGroup = "security.openshift.io"
+)
+
+// capabilityAPIGroups maps capabilities to API groups, that provide them
+var capabilityAPIGroups = map[Capability]string{
+	CapabilityTemplates:                  TemplateAPIGroup,
+	CapabilityRoutes:                     RouteAPIGroup,
+	CapabilitySecurityContextConstraints: SecurityAPIGroup,
+}
+
+// Capabilities holds platform capabilities of the cluster.
+type Capabilities map[Capability]bool
+
+// DetectCapabilities returns capabilities provided by the served API groups, unless the platform overrides them.
+func DetectCapabilities(groups APIGroups, platform tekton.Platform) Capabilities {
+	capabilities := make(Capabilities, len(capabilityAPIGroups))
+	for capability, group := range capabilityAPIGroups {
+		switch platform {
+		case tekton.PlatformOpenShift:
+			capabilities[capability] = true
+		case tekton.PlatformKubernetes:
+			// No OpenShift capabilities
+		default:
+			if groups[group] {
+				capabilities[capability] = true
+			}
+		}
+	}
+	return capabilities
+}
+
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+// Names returns sorted names of the capabilities.
+func (c Capabilities) Names() []string {
+	names := make([]string, 0, len(c))
+	for capability, available := range c {
+		if available {
+			names = append(names, string(capability))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
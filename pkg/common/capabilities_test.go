@@ -0,0 +1,42 @@
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+var _ = Describe("Capabilities", func() {
+	groups := APIGroups{
+		TemplateAPIGroup: true,
+		RouteAPIGroup:    true,
+		KubeVirtAPIGroup: true,
+	}
+
+	It("should detect capabilities from served API groups", func() {
+		capabilities := DetectCapabilities(groups, tekton.PlatformAuto)
+		Expect(capabilities.Has(CapabilityTemplates)).To(BeTrue())
+		Expect(capabilities.Has(CapabilityRoutes)).To(BeTrue())
+		Expect(capabilities.Has(CapabilitySecurityContextConstraints)).To(BeFalse())
+	})
+
+	It("should detect capabilities when platform is not set", func() {
+		capabilities := DetectCapabilities(APIGroups{SecurityAPIGroup: true}, "")
+		Expect(capabilities.Names()).To(Equal([]string{string(CapabilitySecurityContextConstraints)}))
+	})
+
+	It("should assume all capabilities on OpenShift", func() {
+		capabilities := DetectCapabilities(APIGroups{}, tekton.PlatformOpenShift)
+		Expect(capabilities.Names()).To(Equal([]string{
+			string(CapabilityRoutes),
+			string(CapabilitySecurityContextConstraints),
+			string(CapabilityTemplates),
+		}))
+	})
+
+	It("should assume no capabilities on Kubernetes", func() {
+		capabilities := DetectCapabilities(groups, tekton.PlatformKubernetes)
+		Expect(capabilities.Names()).To(BeEmpty())
+	})
+})
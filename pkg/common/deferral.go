@@ -0,0 +1,106 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+// DefaultMaxUpdateWait limits how long updates are deferred, when spec.updateStrategy.maxWait is not set
+const DefaultMaxUpdateWait = time.Hour
+
+// ResourceRunsFunc returns running TaskRuns and PipelineRuns, that reference the found resource.
+type ResourceRunsFunc = func(request *Request, found client.Object) ([]tekton.ResourceReference, error)
+
+// RunningPipelineRuns returns running PipelineRuns of Pipelines. Only runs labeled with their Pipeline are listed,
+// so they are read from the cache, that holds only these runs. The runs are listed once per reconcile,
+// and shared by all resources, whose updates wait for runs.
+func RunningPipelineRuns(request *Request) ([]pipeline.PipelineRun, error) {
+	if request.runningPipelineRuns != nil {
+		return request.runningPipelineRuns, nil
+	}
+
+	pipelineRuns := &pipeline.PipelineRunList{}
+	err := request.Client.List(request.Context, pipelineRuns, client.HasLabels{tektonpipeline.PipelineLabelKey})
+	if err != nil {
+		return nil, err
+	}
+	running := make([]pipeline.PipelineRun, 0, len(pipelineRuns.Items))
+	for i := range pipelineRuns.Items {
+		if !pipelineRuns.Items[i].IsDone() {
+			running = append(running, pipelineRuns.Items[i])
+		}
+	}
+	request.runningPipelineRuns = running
+	return running, nil
+}
+
+// MaxUpdateWait returns how long updates of resources referenced by running runs are deferred.
+func MaxUpdateWait(request *Request) time.Duration {
+	if maxWait := request.Instance.Spec.UpdateStrategy.MaxWait; maxWait != nil {
+		return maxWait.Duration
+	}
+	return DefaultMaxUpdateWait
+}
+
+// FindPendingUpdate returns the pending update of the resource deferred for the reason, or nil.
+func FindPendingUpdate(pendingUpdates []tekton.PendingUpdate, resource client.Object, reason tekton.PendingUpdateReason) *tekton.PendingUpdate {
+	kind := ObjectKind(resource)
+	for i := range pendingUpdates {
+		pending := &pendingUpdates[i]
+		if pending.Reason == reason && pending.Kind == kind && pending.Namespace == resource.GetNamespace() && pending.Name == resource.GetName() {
+			return pending
+		}
+	}
+	return nil
+}
+
+// shouldDeferUpdate returns true, if the update of the resource has to wait for the runs,
+// i.e. some runs reference the resource and its update was not deferred longer than the max wait.
+func shouldDeferUpdate(request *Request, resource client.Object, runs []tekton.ResourceReference) bool {
+	if len(runs) == 0 {
+		return false
+	}
+
+	kind := ObjectKind(resource)
+	pending := FindPendingUpdate(request.Instance.Status.PendingUpdates, resource, tekton.PendingUpdateReasonRunsInProgress)
+	if pending == nil {
+		RecordEvent(request, v1.EventTypeNormal, EventReasonUpdateDeferred,
+			fmt.Sprintf("Update of %s %s is deferred, while %d runs reference it", kind, resource.GetName(), len(runs)))
+		return true
+	}
+
+	maxWait := MaxUpdateWait(request)
+	if time.Since(pending.Since.Time) < maxWait {
+		return true
+	}
+	request.Logger.Info(fmt.Sprintf("Updating %s %s, because its update was deferred longer than %s", kind, resource.GetName(), maxWait))
+	RecordEvent(request, v1.EventTypeWarning, EventReasonUpdateMaxWaitExceeded,
+		fmt.Sprintf("Updated %s %s, while %d runs reference it, because the update was deferred longer than %s",
+			kind, resource.GetName(), len(runs), maxWait))
+	return false
+}
+
+// UpdateDeferredResult returns a result for a resource, whose update is deferred for the reason.
+// Runs are the running TaskRuns and PipelineRuns, that reference the resource.
+func UpdateDeferredResult(resource client.Object, reason tekton.PendingUpdateReason, runs []tekton.ResourceReference) ReconcileResult {
+	message := "Update is deferred until the next update window"
+	if reason == tekton.PendingUpdateReasonRunsInProgress {
+		message = fmt.Sprintf("Update is deferred, while %d runs reference the resource", len(runs))
+	}
+	return ReconcileResult{
+		Status: ResourceStatus{
+			Progressing: &message,
+		},
+		Resource:        resource,
+		OperationResult: OperationResultDeferred,
+		DeferReason:     reason,
+		Runs:            runs,
+	}
+}
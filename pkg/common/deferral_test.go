@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Deferral", func() {
+	var request Request
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(pipeline.AddToScheme(s)).To(Succeed())
+		request = Request{
+			Client:  fake.NewFakeClientWithScheme(s),
+			Context: context.Background(),
+		}
+	})
+
+	pipelineRun := func(name string, labels map[string]string, done bool) *pipeline.PipelineRun {
+		run := &pipeline.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		}
+		if done {
+			run.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue})
+		}
+		return run
+	}
+
+	It("should list only running PipelineRuns of Pipelines", func() {
+		pipelineLabels := map[string]string{tektonpipeline.PipelineLabelKey: "test-pipeline"}
+		for _, run := range []*pipeline.PipelineRun{
+			pipelineRun("running", pipelineLabels, false),
+			pipelineRun("done", pipelineLabels, true),
+			pipelineRun("without-pipeline", nil, false),
+		} {
+			Expect(request.Client.Create(request.Context, run)).To(Succeed())
+		}
+
+		runs, err := RunningPipelineRuns(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].Name).To(Equal("running"))
+	})
+
+	It("should list PipelineRuns once per request", func() {
+		pipelineLabels := map[string]string{tektonpipeline.PipelineLabelKey: "test-pipeline"}
+		Expect(request.Client.Create(request.Context, pipelineRun("first", pipelineLabels, false))).To(Succeed())
+
+		runs, err := RunningPipelineRuns(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+
+		Expect(request.Client.Create(request.Context, pipelineRun("second", pipelineLabels, false))).To(Succeed())
+		runs, err = RunningPipelineRuns(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(1), "runs should be shared within the request")
+	})
+})
@@ -0,0 +1,217 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceGroup is a set of resources, that are reconciled and cleaned up together.
+type ResourceGroup struct {
+	// Name identifies the group within an operand.
+	Name string
+
+	// DependsOn lists names of groups, that have to be reconciled before this group.
+	// Dependencies are cleaned up after this group.
+	DependsOn []string
+
+	// Resources are the objects managed by this group.
+	// They are reported when the group is skipped, and deleted during cleanup.
+	Resources []client.Object
+
+	// Funcs create or update the resources of this group.
+	Funcs []ReconcileFunc
+}
+
+// SortByDependencies returns names ordered so that every name comes after all of its dependencies.
+// Names without a mutual dependency keep their original order.
+func SortByDependencies(names []string, dependencies map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	for _, name := range names {
+		for _, dependency := range dependencies[name] {
+			if !known[dependency] {
+				return nil, fmt.Errorf("%s depends on unknown %s", name, dependency)
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	added := make(map[string]bool, len(names))
+	for len(sorted) < len(names) {
+		progress := false
+		for _, name := range names {
+			if added[name] || !allAdded(dependencies[name], added) {
+				continue
+			}
+			sorted = append(sorted, name)
+			added[name] = true
+			progress = true
+		}
+		if !progress {
+			var remaining []string
+			for _, name := range names {
+				if !added[name] {
+					remaining = append(remaining, name)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between: %s", strings.Join(remaining, ", "))
+		}
+	}
+	return sorted, nil
+}
+
+// FailedDependency returns the first dependency, that is marked as failed.
+func FailedDependency(dependencies []string, failed map[string]bool) (string, bool) {
+	for _, dependency := range dependencies {
+		if failed[dependency] {
+			return dependency, true
+		}
+	}
+	return "", false
+}
+
+// AnyFailed returns true if any of the results is degraded.
+func AnyFailed(results []ReconcileResult) bool {
+	for _, result := range results {
+		if result.Status.Degraded != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileGroups reconciles resource groups in dependency order.
+// If a group fails, resources of all groups that depend on it are not reconciled
+// and are reported as skipped.
+func ReconcileGroups(request *Request, groups ...ResourceGroup) ([]ReconcileResult, error) {
+	sorted, err := sortGroups(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ReconcileResult
+	failed := make(map[string]bool, len(sorted))
+	for _, group := range sorted {
+		if dependency, ok := FailedDependency(group.DependsOn, failed); ok {
+			failed[group.Name] = true
+			message := fmt.Sprintf("Skipped, because prerequisite %s of %s failed", dependency, group.Name)
+			request.Logger.Info(fmt.Sprintf("Skipping resource group %s, prerequisite %s failed", group.Name, dependency))
+			for _, resource := range group.Resources {
+				results = append(results, SkippedResult(resource, message))
+			}
+			continue
+		}
+
+		groupResults, err := CollectResourceStatus(request, group.Funcs...)
+		if err != nil {
+			return nil, err
+		}
+		if AnyFailed(groupResults) {
+			failed[group.Name] = true
+		}
+		results = append(results, groupResults...)
+	}
+	return results, nil
+}
+
+// CleanupGroups deletes resources of the groups in reverse dependency order.
+// Resources of a group are not deleted until all groups depending on it are deleted.
+func CleanupGroups(request *Request, groups ...ResourceGroup) ([]CleanupResult, error) {
+	sorted, err := sortGroups(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CleanupResult
+	pending := make(map[string]bool, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		group := sorted[i]
+		if hasPendingDependent(group.Name, sorted, pending) {
+			pending[group.Name] = true
+			for _, resource := range group.Resources {
+				results = append(results, CleanupResult{Resource: resource, Deleted: false})
+			}
+			continue
+		}
+
+		resources := make([]client.Object, 0, len(group.Resources))
+		for _, resource := range group.Resources {
+			resources = append(resources, resource.DeepCopyObject().(client.Object))
+		}
+		groupResults, err := DeleteAll(request, resources...)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range groupResults {
+			if !result.Deleted {
+				pending[group.Name] = true
+			}
+		}
+		results = append(results, groupResults...)
+	}
+	return results, nil
+}
+
+// SkippedResult returns a result for a resource, that was not reconciled.
+func SkippedResult(resource client.Object, message string) ReconcileResult {
+	return ReconcileResult{
+		Status: ResourceStatus{
+			NotAvailable: &message,
+			Degraded:     &message,
+		},
+		Resource:        resource,
+		OperationResult: OperationResultSkipped,
+	}
+}
+
+func sortGroups(groups []ResourceGroup) ([]ResourceGroup, error) {
+	names := make([]string, 0, len(groups))
+	dependencies := make(map[string][]string, len(groups))
+	byName := make(map[string]ResourceGroup, len(groups))
+	for _, group := range groups {
+		if _, exists := byName[group.Name]; exists {
+			return nil, fmt.Errorf("duplicate resource group: %s", group.Name)
+		}
+		names = append(names, group.Name)
+		dependencies[group.Name] = group.DependsOn
+		byName[group.Name] = group
+	}
+
+	sortedNames, err := SortByDependencies(names, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]ResourceGroup, 0, len(sortedNames))
+	for _, name := range sortedNames {
+		sorted = append(sorted, byName[name])
+	}
+	return sorted, nil
+}
+
+func hasPendingDependent(name string, groups []ResourceGroup, pending map[string]bool) bool {
+	for _, group := range groups {
+		if !pending[group.Name] {
+			continue
+		}
+		for _, dependency := range group.DependsOn {
+			if dependency == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allAdded(names []string, added map[string]bool) bool {
+	for _, name := range names {
+		if !added[name] {
+			return false
+		}
+	}
+	return true
+}
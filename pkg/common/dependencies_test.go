@@ -0,0 +1,158 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Dependencies", func() {
+	Context("SortByDependencies", func() {
+		It("should keep order of independent names", func() {
+			sorted, err := SortByDependencies([]string{"a", "b", "c"}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sorted).To(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("should sort dependencies first", func() {
+			sorted, err := SortByDependencies([]string{"a", "b", "c"}, map[string][]string{
+				"a": {"c"},
+				"b": {"a"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sorted).To(Equal([]string{"c", "a", "b"}))
+		})
+
+		It("should fail on unknown dependency", func() {
+			_, err := SortByDependencies([]string{"a"}, map[string][]string{
+				"a": {"missing"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("unknown missing")))
+		})
+
+		It("should fail on cycle", func() {
+			_, err := SortByDependencies([]string{"a", "b", "c"}, map[string][]string{
+				"a": {"b"},
+				"b": {"a"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("a, b")))
+		})
+	})
+
+	Context("Resource groups", func() {
+		var request Request
+
+		BeforeEach(func() {
+			s := scheme.Scheme
+			Expect(tekton.AddToScheme(s)).ToNot(HaveOccurred())
+
+			request = Request{
+				Client:  fake.NewFakeClientWithScheme(s),
+				Context: context.Background(),
+				Instance: &tekton.TektonTasks{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       tektonResourceKind,
+						APIVersion: tekton.GroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+					},
+				},
+				Logger:       log,
+				VersionCache: VersionCache{},
+			}
+		})
+
+		It("should reconcile groups in dependency order", func() {
+			var order []string
+			groupFunc := func(name string) ReconcileFunc {
+				return func(_ *Request) (ReconcileResult, error) {
+					order = append(order, name)
+					return ReconcileResult{Resource: newConfigMap(name)}, nil
+				}
+			}
+
+			results, err := ReconcileGroups(&request,
+				ResourceGroup{Name: "dependent", DependsOn: []string{"prerequisite"}, Funcs: []ReconcileFunc{groupFunc("dependent")}},
+				ResourceGroup{Name: "prerequisite", Funcs: []ReconcileFunc{groupFunc("prerequisite")}},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(order).To(Equal([]string{"prerequisite", "dependent"}))
+		})
+
+		It("should skip group when prerequisite failed", func() {
+			called := false
+			message := "failed"
+			results, err := ReconcileGroups(&request,
+				ResourceGroup{
+					Name: "prerequisite",
+					Funcs: []ReconcileFunc{func(_ *Request) (ReconcileResult, error) {
+						return ReconcileResult{Resource: newConfigMap("prerequisite"), Status: ResourceStatus{Degraded: &message}}, nil
+					}},
+				},
+				ResourceGroup{
+					Name:      "dependent",
+					DependsOn: []string{"prerequisite"},
+					Resources: []client.Object{newConfigMap("dependent")},
+					Funcs: []ReconcileFunc{func(_ *Request) (ReconcileResult, error) {
+						called = true
+						return ReconcileResult{}, nil
+					}},
+				},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(called).To(BeFalse())
+			Expect(results).To(HaveLen(2))
+			Expect(results[1].Resource.GetName()).To(Equal("dependent"))
+			Expect(results[1].IsSuccess()).To(BeFalse())
+			Expect(results[1].OperationResult).To(Equal(OperationResultSkipped))
+			Expect(*results[1].Status.Degraded).To(ContainSubstring("prerequisite"))
+		})
+
+		It("should not delete prerequisites while dependents are pending", func() {
+			dependent := newConfigMap("dependent")
+			dependent.Finalizers = []string{"test-finalizer"}
+			prerequisite := newConfigMap("prerequisite")
+			for _, obj := range []client.Object{dependent, prerequisite} {
+				Expect(setOwner(&request, obj, false)).To(Succeed())
+				Expect(request.Client.Create(request.Context, obj)).To(Succeed())
+			}
+
+			groups := []ResourceGroup{
+				{Name: "prerequisite", Resources: []client.Object{newConfigMap("prerequisite")}},
+				{Name: "dependent", DependsOn: []string{"prerequisite"}, Resources: []client.Object{newConfigMap("dependent")}},
+			}
+			results, err := CleanupGroups(&request, groups...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Resource.GetName()).To(Equal("dependent"))
+			Expect(results[0].Deleted).To(BeFalse())
+			Expect(results[1].Deleted).To(BeFalse())
+
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(prerequisite), &v1.ConfigMap{})).To(Succeed())
+		})
+	})
+})
+
+func newConfigMap(name string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
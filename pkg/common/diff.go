@@ -0,0 +1,211 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldChange describes a field, that differs between the found and the desired object.
+type FieldChange struct {
+	Path    string      `json:"path"`
+	Found   interface{} `json:"found,omitempty"`
+	Desired interface{} `json:"desired,omitempty"`
+	// Managers lists field managers of the found object, that own the field.
+	Managers []string `json:"managers,omitempty"`
+}
+
+func (f FieldChange) String() string {
+	if len(f.Managers) == 0 {
+		return f.Path
+	}
+	return fmt.Sprintf("%s (by %s)", f.Path, strings.Join(f.Managers, ", "))
+}
+
+// These fields are maintained by the API server and are not compared.
+var ignoredDiffPaths = map[string]bool{
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.creationTimestamp": true,
+	"metadata.uid":               true,
+	"status":                     true,
+}
+
+// ComputeDiff returns fields, that differ between the found and the desired object.
+// Changes are attributed to managers from managedFields of the found object.
+func ComputeDiff(found, desired client.Object) ([]FieldChange, error) {
+	foundMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(found)
+	if err != nil {
+		return nil, err
+	}
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []fieldDiff
+	diffValues(nil, foundMap, desiredMap, &diffs)
+
+	managers, err := parseManagedFields(found.GetManagedFields())
+	if err != nil {
+		return nil, err
+	}
+	var changes []FieldChange
+	for _, diff := range diffs {
+		changes = append(changes, FieldChange{
+			Path:     diff.path.String(),
+			Found:    diff.found,
+			Desired:  diff.desired,
+			Managers: managers.owners(diff.path),
+		})
+	}
+	return changes, nil
+}
+
+// fieldPath is a path to a field kept in segments, because map keys, e.g. label keys, may contain dots.
+type fieldPath []pathSegment
+
+type pathSegment struct {
+	key string
+	// index of a list item, or -1 for map keys
+	index int
+}
+
+func (p fieldPath) child(key string) fieldPath {
+	return append(append(fieldPath{}, p...), pathSegment{key: key, index: -1})
+}
+
+func (p fieldPath) item(index int) fieldPath {
+	return append(append(fieldPath{}, p...), pathSegment{index: index})
+}
+
+// String joins the path for display, list items are appended to their list.
+func (p fieldPath) String() string {
+	var path strings.Builder
+	for i, segment := range p {
+		if segment.index >= 0 {
+			fmt.Fprintf(&path, "[%d]", segment.index)
+			continue
+		}
+		if i > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(segment.key)
+	}
+	return path.String()
+}
+
+type fieldDiff struct {
+	path    fieldPath
+	found   interface{}
+	desired interface{}
+}
+
+func diffValues(path fieldPath, found, desired interface{}, diffs *[]fieldDiff) {
+	if ignoredDiffPaths[path.String()] {
+		return
+	}
+	if reflect.DeepEqual(found, desired) {
+		return
+	}
+
+	foundMap, foundIsMap := found.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if foundIsMap && desiredIsMap {
+		for _, key := range sortedKeys(foundMap, desiredMap) {
+			diffValues(path.child(key), foundMap[key], desiredMap[key], diffs)
+		}
+		return
+	}
+
+	foundList, foundIsList := found.([]interface{})
+	desiredList, desiredIsList := desired.([]interface{})
+	if foundIsList && desiredIsList && len(foundList) == len(desiredList) {
+		for i := range foundList {
+			diffValues(path.item(i), foundList[i], desiredList[i], diffs)
+		}
+		return
+	}
+
+	*diffs = append(*diffs, fieldDiff{
+		path:    path,
+		found:   found,
+		desired: desired,
+	})
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	keySet := map[string]struct{}{}
+	for _, m := range maps {
+		for key := range m {
+			keySet[key] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type managedFieldSets []managedFieldSet
+
+type managedFieldSet struct {
+	manager string
+	fields  map[string]interface{}
+}
+
+func parseManagedFields(entries []metav1.ManagedFieldsEntry) (managedFieldSets, error) {
+	sets := make(managedFieldSets, 0, len(entries))
+	for _, entry := range entries {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, err
+		}
+		manager := entry.Manager
+		if entry.Time != nil {
+			manager = fmt.Sprintf("%s at %s", manager, entry.Time.UTC().Format("2006-01-02T15:04:05Z"))
+		}
+		sets = append(sets, managedFieldSet{manager: manager, fields: fields})
+	}
+	return sets, nil
+}
+
+// owners returns managers, whose field set contains the path.
+// Ownership inside of lists is approximated by ownership of any field in the list.
+func (m managedFieldSets) owners(path fieldPath) []string {
+	var owners []string
+	for _, set := range m {
+		if set.owns(path) {
+			owners = append(owners, set.manager)
+		}
+	}
+	return owners
+}
+
+func (s managedFieldSet) owns(path fieldPath) bool {
+	node := s.fields
+	for _, segment := range path {
+		if segment.index >= 0 {
+			// The list is owned, items are identified by keys, that are not in the path
+			return true
+		}
+		child, ok := node["f:"+segment.key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		node = child
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Diff", func() {
+	It("should return no changes for equal objects", func() {
+		changes, err := ComputeDiff(newTestResource(namespace), newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("should return changed fields", func() {
+		found := newTestResource(namespace)
+		found.Spec.Ports[0].Name = "changed-name"
+		found.Labels["added-label"] = "value"
+
+		changes, err := ComputeDiff(found, newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(ConsistOf(
+			FieldChange{Path: "metadata.labels.added-label", Found: "value"},
+			FieldChange{Path: "spec.ports[0].name", Found: "changed-name", Desired: newTestResource(namespace).Spec.Ports[0].Name},
+		))
+	})
+
+	It("should ignore fields maintained by the API server", func() {
+		found := newTestResource(namespace)
+		found.ResourceVersion = "5"
+		found.Generation = 2
+
+		changes, err := ComputeDiff(found, newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("should attribute changes to field managers", func() {
+		changeTime := metav1.NewTime(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+		found := newTestResource(namespace)
+		found.Spec.Ports[0].Name = "changed-name"
+		found.ManagedFields = []metav1.ManagedFieldsEntry{{
+			Manager:   "kubectl-edit",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			Time:      &changeTime,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:ports":{"k:{\"port\":80,\"protocol\":\"TCP\"}":{"f:name":{}}}}}`)},
+		}, {
+			Manager:   "other-manager",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{}}}`)},
+		}}
+
+		changes, err := ComputeDiff(found, newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Managers).To(Equal([]string{"kubectl-edit at 2022-01-02T03:04:05Z"}))
+		Expect(changes[0].String()).To(Equal("spec.ports[0].name (by kubectl-edit at 2022-01-02T03:04:05Z)"))
+	})
+
+	It("should attribute changes of dotted label keys to field managers", func() {
+		found := newTestResource(namespace)
+		found.Labels["app.kubernetes.io/name"] = "changed"
+		found.ManagedFields = []metav1.ManagedFieldsEntry{{
+			Manager:   "kubectl-label",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app.kubernetes.io/name":{}}}}`)},
+		}}
+		desired := newTestResource(namespace)
+		desired.Labels["app.kubernetes.io/name"] = "desired"
+
+		changes, err := ComputeDiff(found, desired)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Path).To(Equal("metadata.labels.app.kubernetes.io/name"))
+		Expect(changes[0].Managers).To(Equal([]string{"kubectl-label"}))
+	})
+})
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventSource is the component name reported in events emitted by the operator.
+const EventSource = "tekton-tasks-operator"
+
+const (
+	EventReasonResourceCreated  = "ResourceCreated"
+	EventReasonResourceUpdated  = "ResourceUpdated"
+	EventReasonResourceDeleted  = "ResourceDeleted"
+	EventReasonResourcePruned   = "ResourcePruned"
+	EventReasonResourceAdopted  = "ResourceAdopted"
+	EventReasonResourceConflict = "ResourceConflict"
+	EventReasonChangesReverted  = "ChangesReverted"
+
+	EventReasonUpdateDeferred        = "UpdateDeferred"
+	EventReasonUpdateMaxWaitExceeded = "UpdateMaxWaitExceeded"
+
+	EventReasonMultipleCRs        = "MultipleCRs"
+	EventReasonPaused             = "Paused"
+	EventReasonResumed            = "Resumed"
+	EventReasonWaitingForCRDs     = "WaitingForCRDs"
+	EventReasonCRDsInstalled      = "CRDsInstalled"
+	EventReasonCleanupStarted     = "CleanupStarted"
+	EventReasonCleanupFinished    = "CleanupFinished"
+	EventReasonReconcileFailed    = "ReconcileFailed"
+	EventReasonMigrationCompleted = "MigrationCompleted"
+)
+
+// Kubernetes limits the event message size, longer lists of fields are truncated.
+const maxEventFields = 5
+
+// RecordEvent emits an event on the TektonTasks instance. It does nothing if the request has no recorder.
+func RecordEvent(request *Request, eventType, reason, message string) {
+	if request.Recorder == nil || request.Instance == nil {
+		return
+	}
+	request.Recorder.Event(request.Instance, eventType, reason, message)
+}
+
+// RecordObjectEvent emits an event on the given object. It does nothing if the request has no recorder.
+func RecordObjectEvent(request *Request, object client.Object, eventType, reason, message string) {
+	if request.Recorder == nil {
+		return
+	}
+	request.Recorder.Event(object, eventType, reason, message)
+}
+
+// recordOperation emits an event on the TektonTasks instance for a create, update or delete
+// of a managed resource. Updates, that only revert user changes, are reported by ReportRevertedChanges.
+func recordOperation(request *Request, result ReconcileResult) {
+	kind := result.Resource.GetObjectKind().GroupVersionKind().Kind
+	name := result.Resource.GetName()
+	switch result.OperationResult {
+	case OperationResultCreated:
+		countOperation(result.Resource, MetricOperationCreated)
+		RecordEvent(request, v1.EventTypeNormal, EventReasonResourceCreated, fmt.Sprintf("Created %s %s", kind, name))
+	case OperationResultUpdated:
+		if result.DesiredStateChanged() {
+			countOperation(result.Resource, MetricOperationUpdated)
+			RecordEvent(request, v1.EventTypeNormal, EventReasonResourceUpdated, fmt.Sprintf("Updated %s %s", kind, name))
+		}
+	case OperationResultDeleted:
+		countOperation(result.Resource, MetricOperationDeleted)
+		RecordEvent(request, v1.EventTypeNormal, EventReasonResourceDeleted, fmt.Sprintf("Deleted %s %s", kind, name))
+	case OperationResultPruned:
+		countOperation(result.Resource, MetricOperationDeleted)
+		RecordEvent(request, v1.EventTypeNormal, EventReasonResourcePruned,
+			fmt.Sprintf("Deleted %s %s, because it is no longer part of the operator", kind, name))
+	}
+}
+
+// ReportRevertedChanges logs the fields changed when reverting user changes
+// of a resource, and emits an event on the TektonTasks instance.
+func ReportRevertedChanges(request *Request, result ReconcileResult) {
+	countOperation(result.Resource, MetricOperationReverted)
+
+	kind := result.Resource.GetObjectKind().GroupVersionKind().Kind
+	request.Logger.Info(fmt.Sprintf("Changes reverted in %s: %s", kind, result.Resource.GetName()),
+		"kind", kind,
+		"namespace", result.Resource.GetNamespace(),
+		"name", result.Resource.GetName(),
+		"diff", result.Diff,
+	)
+
+	fields := make([]string, 0, len(result.Diff))
+	for i, change := range result.Diff {
+		if i == maxEventFields {
+			fields = append(fields, fmt.Sprintf("and %d more", len(result.Diff)-maxEventFields))
+			break
+		}
+		fields = append(fields, change.String())
+	}
+	message := fmt.Sprintf("Reverted changes of %s %s", kind, result.Resource.GetName())
+	if len(fields) > 0 {
+		message += ": " + strings.Join(fields, "; ")
+	}
+	RecordEvent(request, v1.EventTypeWarning, EventReasonChangesReverted, message)
+	if result.Resource.GetNamespace() != "" {
+		RecordObjectEvent(request, result.Resource, v1.EventTypeWarning, EventReasonChangesReverted, message)
+	}
+}
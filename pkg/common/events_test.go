@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Events", func() {
+	var (
+		request  Request
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		s := scheme.Scheme
+		Expect(tekton.AddToScheme(s)).ToNot(HaveOccurred())
+
+		recorder = record.NewFakeRecorder(10)
+		request = Request{
+			Client:  fake.NewFakeClientWithScheme(s),
+			Context: context.Background(),
+			Instance: &tekton.TektonTasks{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       tektonResourceKind,
+					APIVersion: tekton.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			},
+			Logger:       log,
+			VersionCache: VersionCache{},
+			Recorder:     recorder,
+		}
+	})
+
+	It("should record event when resource is created", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonResourceCreated)))
+	})
+
+	It("should record event when resource is deleted", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(recorder.Events).Should(Receive())
+
+		_, err = Cleanup(&request, newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonResourceDeleted)))
+	})
+
+	It("should not record update event when changes are reverted", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(recorder.Events).Should(Receive())
+
+		resource := newTestResource(namespace)
+		Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).To(Succeed())
+		request.VersionCache.Add(resource)
+
+		resource.Spec.Ports[0].Name = "changed-name"
+		Expect(request.Client.Update(request.Context, resource)).To(Succeed())
+
+		result, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.OperationResult).To(Equal(OperationResultUpdated))
+		Expect(result.DesiredStateChanged()).To(BeFalse())
+		Expect(recorder.Events).ToNot(Receive())
+
+		ReportRevertedChanges(&request, result)
+		Expect(recorder.Events).To(Receive(SatisfyAll(
+			ContainSubstring(EventReasonChangesReverted),
+			ContainSubstring("spec.ports[0].name"),
+		)))
+	})
+
+	It("should not fail without recorder", func() {
+		request.Recorder = nil
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -20,6 +20,7 @@ const (
 
 	AppComponentTektonTasks     AppComponent = "tektonTasks"
 	AppComponentTektonPipelines AppComponent = "tektonPipelines"
+	AppComponentMonitoring      AppComponent = "monitoring"
 	AppKubernetesManagedByValue              = "tekton-tasks-operator"
 )
 
@@ -31,6 +32,8 @@ func AddAppLabels(requestInstance *tekton.TektonTasks, name string, component Ap
 	labels[AppKubernetesComponentLabel] = component.String()
 	labels[AppKubernetesManagedByLabel] = AppKubernetesManagedByValue
 
+	// Unstructured objects return a copy of the labels, so they have to be set back
+	obj.SetLabels(labels)
 	return obj
 }
 
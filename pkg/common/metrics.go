@@ -0,0 +1,125 @@
+package common
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Values of the status label of run metrics
+const (
+	MetricRunStatusSucceeded = "succeeded"
+	MetricRunStatusFailed    = "failed"
+	MetricRunStatusCancelled = "cancelled"
+)
+
+// Runs usually take from seconds to tens of minutes, the buckets range from 10 seconds to about 3 hours
+var runDurationBuckets = prometheus.ExponentialBuckets(10, 2, 11)
+
+// Values of the operation label of TektonOperatorResourceOperations
+const (
+	MetricOperationCreated  = "created"
+	MetricOperationUpdated  = "updated"
+	MetricOperationReverted = "reverted"
+	MetricOperationDeleted  = "deleted"
+)
+
+var (
+	TektonOperatorReconcilingProperly = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tekton_operator_reconciling_properly",
+		Help: "Set to 1 if the reconcile process of all operands completes with no errors, and to 0 otherwise",
+	})
+
+	TektonOperatorReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "tekton_operator_reconcile_duration_seconds",
+		Help: "Duration of the reconciliation of an operand in seconds",
+	}, []string{"operand"})
+
+	TektonOperatorCRDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tekton_operator_cr_degraded",
+		Help: "Set to 1 if the Degraded condition of the TektonTasks CR is True, and to 0 otherwise",
+	})
+
+	TektonOperatorMissingCrds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tekton_operator_missing_crds",
+		Help: "Number of CRDs required by the operator, that are not installed",
+	})
+
+	TektonOperatorResourceOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tekton_operator_resource_operations_total",
+		Help: "Number of managed resources created, updated, reverted or deleted by the operator, by kind",
+	}, []string{"kind", "operation"})
+
+	TektonOperatorManagedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tekton_operator_managed_resources",
+		Help: "Number of resources managed by the operator, by kind and health state",
+	}, []string{"kind", "state"})
+
+	TektonOperatorInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tekton_operator_info",
+		Help: "Versions of the operator and of the deployed tasks bundle, the value is always 1",
+	}, []string{"operator_version", "tasks_version"})
+
+	TektonOperatorTaskRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tekton_operator_task_runs_total",
+		Help: "Number of finished TaskRuns of ClusterTasks managed by the operator, by task and status",
+	}, []string{"task", "status"})
+
+	TektonOperatorTaskRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tekton_operator_task_run_duration_seconds",
+		Help:    "Duration of finished TaskRuns of ClusterTasks managed by the operator in seconds, by task and status",
+		Buckets: runDurationBuckets,
+	}, []string{"task", "status"})
+
+	TektonOperatorPipelineRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tekton_operator_pipeline_runs_total",
+		Help: "Number of finished PipelineRuns of Pipelines managed by the operator, by pipeline and status",
+	}, []string{"pipeline", "status"})
+
+	TektonOperatorPipelineRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tekton_operator_pipeline_run_duration_seconds",
+		Help:    "Duration of finished PipelineRuns of Pipelines managed by the operator in seconds, by pipeline and status",
+		Buckets: runDurationBuckets,
+	}, []string{"pipeline", "status"})
+
+	TektonOperatorBundleLoadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tekton_operator_bundle_load_failures_total",
+		Help: "Number of failures to load the tasks or pipelines bundle",
+	}, []string{"bundle"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		TektonOperatorReconcilingProperly,
+		TektonOperatorReconcileDuration,
+		TektonOperatorCRDegraded,
+		TektonOperatorMissingCrds,
+		TektonOperatorResourceOperations,
+		TektonOperatorManagedResources,
+		TektonOperatorInfo,
+		TektonOperatorTaskRuns,
+		TektonOperatorTaskRunDuration,
+		TektonOperatorPipelineRuns,
+		TektonOperatorPipelineRunDuration,
+		TektonOperatorBundleLoadFailures,
+	)
+}
+
+// countOperation increments the counter of operations on resources of the kind of the object.
+func countOperation(obj client.Object, operation string) {
+	TektonOperatorResourceOperations.WithLabelValues(ObjectKind(obj), operation).Inc()
+}
+
+// ObjectKind returns the kind of the object. Objects without TypeMeta are looked up in the Scheme.
+func ObjectKind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	gvk, err := apiutil.GVKForObject(obj, Scheme)
+	if err != nil {
+		return ""
+	}
+	return gvk.Kind
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("Metrics", func() {
+	var request Request
+
+	BeforeEach(func() {
+		s := scheme.Scheme
+		Expect(tekton.AddToScheme(s)).ToNot(HaveOccurred())
+
+		request = Request{
+			Client:  fake.NewFakeClientWithScheme(s),
+			Context: context.Background(),
+			Instance: &tekton.TektonTasks{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       tektonResourceKind,
+					APIVersion: tekton.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			},
+			Logger:       log,
+			VersionCache: VersionCache{},
+		}
+		TektonOperatorResourceOperations.Reset()
+	})
+
+	It("should register metrics with the controller-runtime registry", func() {
+		Expect(metrics.Registry.Unregister(TektonOperatorReconcilingProperly)).To(BeTrue())
+		Expect(metrics.Registry.Register(TektonOperatorReconcilingProperly)).To(Succeed())
+	})
+
+	It("should count created resources by kind", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(TektonOperatorResourceOperations.WithLabelValues("Service", MetricOperationCreated))).To(Equal(1.0))
+	})
+
+	It("should count deleted resources by kind", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Cleanup(&request, newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(TektonOperatorResourceOperations.WithLabelValues("Service", MetricOperationDeleted))).To(Equal(1.0))
+	})
+
+	It("should count reverted resources by kind", func() {
+		ReportRevertedChanges(&request, ReconcileResult{Resource: newTestResource(namespace)})
+		Expect(testutil.ToFloat64(TektonOperatorResourceOperations.WithLabelValues("Service", MetricOperationReverted))).To(Equal(1.0))
+	})
+
+	It("should return kind of object without TypeMeta", func() {
+		Expect(ObjectKind(&v1.ConfigMap{})).To(Equal("ConfigMap"))
+	})
+})
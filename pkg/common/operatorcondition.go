@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	olmconditions "github.com/operator-framework/operator-lib/conditions"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=operators.coreos.com,resources=operatorconditions,verbs=get;update;patch
+
+// OperatorConditionNameKey is set by OLM to the name of the OperatorCondition of the operator
+const OperatorConditionNameKey = "OPERATOR_CONDITION_NAME"
+
+// UpgradeableCondition reports to OLM, whether the operator can be upgraded.
+// It is safe for concurrent use.
+type UpgradeableCondition struct {
+	// condition is nil, when the operator is not deployed by OLM
+	condition olmconditions.Condition
+
+	lock sync.Mutex
+	last *metav1.Condition
+}
+
+// NewUpgradeableCondition returns the Upgradeable condition of the OperatorCondition created by OLM.
+// When the operator is not deployed by OLM, the returned condition does nothing.
+func NewUpgradeableCondition(cl client.Client) (*UpgradeableCondition, error) {
+	if os.Getenv(OperatorConditionNameKey) == "" {
+		return &UpgradeableCondition{}, nil
+	}
+	condition, err := olmconditions.InClusterFactory{Client: cl}.NewCondition(operatorsv2.ConditionType(operatorsv2.Upgradeable))
+	if err != nil {
+		return nil, err
+	}
+	return &UpgradeableCondition{condition: condition}, nil
+}
+
+// Report sets the Upgradeable condition from the status of the TektonTasks CR.
+// The OperatorCondition is updated only when the condition changes.
+func (u *UpgradeableCondition) Report(ctx context.Context, instance *tekton.TektonTasks) error {
+	if u.condition == nil {
+		return nil
+	}
+
+	desired := upgradeable(instance)
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.last != nil && u.last.Status == desired.Status && u.last.Reason == desired.Reason && u.last.Message == desired.Message {
+		return nil
+	}
+
+	err := u.condition.Set(ctx, desired.Status,
+		olmconditions.WithReason(desired.Reason),
+		olmconditions.WithMessage(desired.Message))
+	if err != nil {
+		// The condition is set again by the next reconcile
+		u.last = nil
+		return err
+	}
+	u.last = &desired
+	return nil
+}
+
+// upgradeable returns the Upgradeable condition. The operator should not be upgraded,
+// while the CR is degraded or upgrade migrations of the previous upgrade have not completed.
+// The CR is degraded with the Reconciling reason during each reconcile, which does not block upgrades.
+func upgradeable(instance *tekton.TektonTasks) metav1.Condition {
+	var pending []string
+	for _, migration := range instance.Status.Migrations {
+		if !migration.Completed {
+			pending = append(pending, migration.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return metav1.Condition{
+			Type:    string(operatorsv2.Upgradeable),
+			Status:  metav1.ConditionFalse,
+			Reason:  tekton.ReasonMigrationsPending,
+			Message: fmt.Sprintf("Upgrade migrations have not completed: %s", strings.Join(pending, ", ")),
+		}
+	}
+
+	degraded := conditionsv1.FindStatusCondition(instance.Status.Conditions, conditionsv1.ConditionDegraded)
+	if degraded != nil && degraded.Status == v1.ConditionTrue && degraded.Reason != tekton.ReasonReconciling {
+		reason := degraded.Reason
+		if reason == "" {
+			reason = tekton.ReasonResourcesDegraded
+		}
+		return metav1.Condition{
+			Type:    string(operatorsv2.Upgradeable),
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: degraded.Message,
+		}
+	}
+
+	return metav1.Condition{
+		Type:    string(operatorsv2.Upgradeable),
+		Status:  metav1.ConditionTrue,
+		Reason:  tekton.ReasonAsExpected,
+		Message: "The operator can be upgraded",
+	}
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	olmconditions "github.com/operator-framework/operator-lib/conditions"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+type fakeCondition struct {
+	condition *metav1.Condition
+	sets      int
+	err       error
+}
+
+func (f *fakeCondition) Get(_ context.Context) (*metav1.Condition, error) {
+	return f.condition, nil
+}
+
+func (f *fakeCondition) Set(_ context.Context, status metav1.ConditionStatus, options ...olmconditions.Option) error {
+	f.sets++
+	if f.err != nil {
+		return f.err
+	}
+	f.condition = &metav1.Condition{Status: status}
+	for _, option := range options {
+		option(f.condition)
+	}
+	return nil
+}
+
+var _ = Describe("Upgradeable condition", func() {
+	var (
+		condition   *fakeCondition
+		upgradeable *UpgradeableCondition
+		instance    *tekton.TektonTasks
+	)
+
+	BeforeEach(func() {
+		condition = &fakeCondition{}
+		upgradeable = &UpgradeableCondition{condition: condition}
+		instance = &tekton.TektonTasks{}
+	})
+
+	It("should do nothing when not deployed by OLM", func() {
+		upgradeable, err := NewUpgradeableCondition(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+	})
+
+	It("should be upgradeable when the CR is healthy", func() {
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should not be upgradeable when the CR is degraded", func() {
+		conditionsv1.SetStatusCondition(&instance.Status.Conditions, conditionsv1.Condition{
+			Type:    conditionsv1.ConditionDegraded,
+			Status:  v1.ConditionTrue,
+			Reason:  tekton.ReasonResourceConflict,
+			Message: "test message",
+		})
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.condition.Reason).To(Equal(tekton.ReasonResourceConflict))
+		Expect(condition.condition.Message).To(Equal("test message"))
+	})
+
+	It("should be upgradeable while the CR is being reconciled", func() {
+		conditionsv1.SetStatusCondition(&instance.Status.Conditions, conditionsv1.Condition{
+			Type:    conditionsv1.ConditionDegraded,
+			Status:  v1.ConditionTrue,
+			Reason:  tekton.ReasonReconciling,
+			Message: "Reconciling Tekton tasks resources",
+		})
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should not be upgradeable while migrations are pending", func() {
+		instance.Status.Migrations = []tekton.MigrationStatus{
+			{Name: "first", Completed: true},
+			{Name: "second"},
+		}
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.condition.Reason).To(Equal(tekton.ReasonMigrationsPending))
+		Expect(condition.condition.Message).To(HaveSuffix(": second"))
+	})
+
+	It("should set the condition only when it changes", func() {
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.sets).To(Equal(1))
+
+		instance.Status.Migrations = []tekton.MigrationStatus{{Name: "pending"}}
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.sets).To(Equal(2))
+	})
+
+	It("should set the condition again after failure", func() {
+		condition.err = errors.New("test error")
+		Expect(upgradeable.Report(context.Background(), instance)).To(MatchError("test error"))
+		condition.err = nil
+		Expect(upgradeable.Report(context.Background(), instance)).To(Succeed())
+		Expect(condition.sets).To(Equal(2))
+	})
+})
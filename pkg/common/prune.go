@@ -0,0 +1,122 @@
+package common
+
+import (
+	"fmt"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// PruneResources deletes resources labeled as managed by the operator for the given component,
+// that are not in the desired set. Only resources owned by the request instance are deleted.
+// While no update window is open, the resources are reported as deferred and are not deleted.
+func PruneResources(request *Request, component AppComponent, types []client.Object, desired []client.Object) ([]ReconcileResult, error) {
+	desiredKeys := make(map[cacheKey]struct{}, len(desired))
+	for _, obj := range desired {
+		gvk, err := apiutil.GVKForObject(obj, request.Client.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		desiredKeys[cacheKey{Kind: gvk.Kind, Name: obj.GetName(), Namespace: obj.GetNamespace()}] = struct{}{}
+	}
+
+	var results []ReconcileResult
+	for _, t := range types {
+		found, err := listManagedResources(request, component, t)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range found {
+			if _, ok := desiredKeys[cacheKeyFromObj(obj)]; ok {
+				continue
+			}
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+
+			isOwned, err := isResourceOwned(request, obj.DeepCopyObject().(client.Object), obj)
+			if err != nil {
+				return nil, err
+			}
+			if !isOwned {
+				continue
+			}
+			if request.UpdateWindowClosed {
+				results = append(results, UpdateDeferredResult(obj, tekton.PendingUpdateReasonOutsideUpdateWindow, nil))
+				continue
+			}
+
+			err = request.Client.Delete(request.Context, obj)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				request.Logger.Error(err, fmt.Sprintf("Error pruning \"%s\": %s", obj.GetName(), err))
+				return nil, err
+			}
+			logOperation(OperationResultPruned, obj, request.Logger)
+			result := ReconcileResult{
+				Resource:        obj,
+				OperationResult: OperationResultPruned,
+			}
+			recordOperation(request, result)
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// GroupResources returns resources of all groups.
+func GroupResources(groups []ResourceGroup) []client.Object {
+	var resources []client.Object
+	for _, group := range groups {
+		resources = append(resources, group.Resources...)
+	}
+	return resources
+}
+
+func listManagedResources(request *Request, component AppComponent, obj client.Object) ([]client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, request.Client.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	listObj, err := request.Client.Scheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	list, ok := listObj.(client.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list", gvk.Kind+"List")
+	}
+
+	err = request.Client.List(request.Context, list, client.MatchingLabels{
+		AppKubernetesManagedByLabel: AppKubernetesManagedByValue,
+		AppKubernetesComponentLabel: component.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		itemObj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		// Typed objects returned by the client may not have kind set
+		itemObj.GetObjectKind().SetGroupVersionKind(gvk)
+		objects = append(objects, itemObj)
+	}
+	return objects, nil
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("PruneResources", func() {
+	const component = AppComponent("testComponent")
+
+	var request Request
+
+	BeforeEach(func() {
+		s := scheme.Scheme
+		Expect(tekton.AddToScheme(s)).ToNot(HaveOccurred())
+
+		request = Request{
+			Client:  fake.NewFakeClientWithScheme(s),
+			Context: context.Background(),
+			Instance: &tekton.TektonTasks{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       tektonResourceKind,
+					APIVersion: tekton.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			},
+			Logger:       log,
+			VersionCache: VersionCache{},
+		}
+	})
+
+	createManaged := func(name string, owned bool) *v1.ConfigMap {
+		cm := newConfigMap(name)
+		AddAppLabels(request.Instance, "test", component, cm)
+		if owned {
+			Expect(setOwner(&request, cm, false)).To(Succeed())
+		}
+		Expect(request.Client.Create(request.Context, cm)).To(Succeed())
+		return cm
+	}
+
+	expectExists := func(obj client.Object, exists bool) {
+		err := request.Client.Get(request.Context, client.ObjectKeyFromObject(obj), &v1.ConfigMap{})
+		if exists {
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		} else {
+			ExpectWithOffset(1, errors.IsNotFound(err)).To(BeTrue())
+		}
+	}
+
+	It("should delete owned resources, that are not desired", func() {
+		desired := createManaged("desired", true)
+		removed := createManaged("removed", true)
+
+		results, err := PruneResources(&request, component, []client.Object{&v1.ConfigMap{}}, []client.Object{newConfigMap("desired")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].OperationResult).To(Equal(OperationResultPruned))
+		Expect(results[0].Resource.GetName()).To(Equal("removed"))
+		Expect(results[0].Resource.GetObjectKind().GroupVersionKind().Kind).To(Equal("ConfigMap"))
+
+		expectExists(desired, true)
+		expectExists(removed, false)
+	})
+
+	It("should not delete resources, while no update window is open", func() {
+		removed := createManaged("removed", true)
+		request.UpdateWindowClosed = true
+
+		results, err := PruneResources(&request, component, []client.Object{&v1.ConfigMap{}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].OperationResult).To(Equal(OperationResultDeferred))
+		Expect(results[0].DeferReason).To(Equal(tekton.PendingUpdateReasonOutsideUpdateWindow))
+
+		expectExists(removed, true)
+	})
+
+	It("should not delete resources, that are not owned", func() {
+		notOwned := createManaged("not-owned", false)
+
+		results, err := PruneResources(&request, component, []client.Object{&v1.ConfigMap{}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+		expectExists(notOwned, true)
+	})
+
+	It("should not delete resources of other components", func() {
+		other := newConfigMap("other")
+		AddAppLabels(request.Instance, "test", AppComponent("otherComponent"), other)
+		Expect(setOwner(&request, other, false)).To(Succeed())
+		Expect(request.Client.Create(request.Context, other)).To(Succeed())
+
+		results, err := PruneResources(&request, component, []client.Object{&v1.ConfigMap{}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+		expectExists(other, true)
+	})
+})
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+const (
+	ReadyzBundles    = "bundles"
+	ReadyzCrds       = "crds"
+	ReadyzController = "controller"
+	ReadyzReconcile  = "reconcile"
+)
+
+// Readiness tracks the state of the operator, that is reported by the readiness checks.
+// It is safe for concurrent use.
+type Readiness struct {
+	lock sync.RWMutex
+
+	bundlesLoaded     bool
+	crdsPresent       bool
+	controllerStarted bool
+
+	reconciled        bool
+	lastReconcileTime time.Time
+	lastReconcileErr  error
+
+	// reconcileWindow is the maximum age of the last successful reconcile, 0 disables the check of the age.
+	reconcileWindow time.Duration
+	// elected is closed when this replica becomes the leader. Standby replicas do not run
+	// the controller, so only the bundles are checked, to not block rollouts.
+	elected <-chan struct{}
+	now     func() time.Time
+}
+
+func NewReadiness(reconcileWindow time.Duration, elected <-chan struct{}) *Readiness {
+	return &Readiness{
+		reconcileWindow: reconcileWindow,
+		elected:         elected,
+		now:             time.Now,
+	}
+}
+
+// Checks returns the readiness checks by their names.
+func (r *Readiness) Checks() map[string]healthz.Checker {
+	return map[string]healthz.Checker{
+		ReadyzBundles:    r.checkBundles,
+		ReadyzCrds:       r.checkCrds,
+		ReadyzController: r.checkController,
+		ReadyzReconcile:  r.checkReconcile,
+	}
+}
+
+func (r *Readiness) SetBundlesLoaded() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.bundlesLoaded = true
+}
+
+func (r *Readiness) SetCrdsPresent(present bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.crdsPresent = present
+}
+
+func (r *Readiness) SetControllerStarted(started bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.controllerStarted = started
+}
+
+// ReconcileCompleted records the result of a reconcile of the TektonTasks CR.
+func (r *Readiness) ReconcileCompleted(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reconciled = true
+	r.lastReconcileTime = r.now()
+	r.lastReconcileErr = err
+}
+
+// NothingToReconcile records, that the TektonTasks CR does not exist,
+// so no periodic reconciles are expected.
+func (r *Readiness) NothingToReconcile() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reconciled = false
+	r.lastReconcileErr = nil
+}
+
+func (r *Readiness) checkBundles(_ *http.Request) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if !r.bundlesLoaded {
+		return errors.New("bundles are not loaded")
+	}
+	return nil
+}
+
+func (r *Readiness) checkCrds(_ *http.Request) error {
+	if !r.isElected() {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if !r.crdsPresent {
+		return errors.New("required CRDs are not installed")
+	}
+	return nil
+}
+
+func (r *Readiness) checkController(_ *http.Request) error {
+	if !r.isElected() {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if !r.controllerStarted {
+		return errors.New("controller is not started")
+	}
+	return nil
+}
+
+func (r *Readiness) checkReconcile(_ *http.Request) error {
+	if !r.isElected() {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if !r.reconciled {
+		return nil
+	}
+	if r.lastReconcileErr != nil {
+		return fmt.Errorf("last reconcile failed: %w", r.lastReconcileErr)
+	}
+	if r.reconcileWindow > 0 {
+		if age := r.now().Sub(r.lastReconcileTime); age > r.reconcileWindow {
+			return fmt.Errorf("last reconcile completed %s ago", age.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+func (r *Readiness) isElected() bool {
+	if r.elected == nil {
+		return true
+	}
+	select {
+	case <-r.elected:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Readiness", func() {
+	const window = 30 * time.Minute
+
+	var (
+		readiness *Readiness
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		readiness = NewReadiness(window, nil)
+		readiness.now = func() time.Time { return now }
+	})
+
+	check := func(name string) error {
+		return readiness.Checks()[name](nil)
+	}
+
+	It("should expose checks separately", func() {
+		Expect(readiness.Checks()).To(HaveLen(4))
+		Expect(readiness.Checks()).To(HaveKey(ReadyzBundles))
+		Expect(readiness.Checks()).To(HaveKey(ReadyzCrds))
+		Expect(readiness.Checks()).To(HaveKey(ReadyzController))
+		Expect(readiness.Checks()).To(HaveKey(ReadyzReconcile))
+	})
+
+	It("should not be ready before bundles are loaded", func() {
+		Expect(check(ReadyzBundles)).To(HaveOccurred())
+		readiness.SetBundlesLoaded()
+		Expect(check(ReadyzBundles)).To(Succeed())
+	})
+
+	It("should not be ready while CRDs are missing", func() {
+		Expect(check(ReadyzCrds)).To(HaveOccurred())
+		readiness.SetCrdsPresent(true)
+		Expect(check(ReadyzCrds)).To(Succeed())
+		readiness.SetCrdsPresent(false)
+		Expect(check(ReadyzCrds)).To(HaveOccurred())
+	})
+
+	It("should not be ready before controller is started", func() {
+		Expect(check(ReadyzController)).To(HaveOccurred())
+		readiness.SetControllerStarted(true)
+		Expect(check(ReadyzController)).To(Succeed())
+		readiness.SetControllerStarted(false)
+		Expect(check(ReadyzController)).To(HaveOccurred())
+	})
+
+	Context("reconcile", func() {
+		It("should be ready before first reconcile", func() {
+			Expect(check(ReadyzReconcile)).To(Succeed())
+		})
+
+		It("should be ready after successful reconcile", func() {
+			readiness.ReconcileCompleted(nil)
+			Expect(check(ReadyzReconcile)).To(Succeed())
+		})
+
+		It("should not be ready after failed reconcile", func() {
+			readiness.ReconcileCompleted(errors.New("test error"))
+			Expect(check(ReadyzReconcile)).To(MatchError(ContainSubstring("test error")))
+		})
+
+		It("should not be ready when last reconcile is older than window", func() {
+			readiness.ReconcileCompleted(nil)
+			now = now.Add(window + time.Minute)
+			Expect(check(ReadyzReconcile)).To(HaveOccurred())
+		})
+
+		It("should ignore age of last reconcile when window is 0", func() {
+			readiness.reconcileWindow = 0
+			readiness.ReconcileCompleted(nil)
+			now = now.Add(window + time.Minute)
+			Expect(check(ReadyzReconcile)).To(Succeed())
+		})
+
+		It("should be ready when there is nothing to reconcile", func() {
+			readiness.ReconcileCompleted(errors.New("test error"))
+			readiness.NothingToReconcile()
+			now = now.Add(window + time.Minute)
+			Expect(check(ReadyzReconcile)).To(Succeed())
+		})
+	})
+
+	It("should check only bundles on standby replica", func() {
+		elected := make(chan struct{})
+		readiness = NewReadiness(window, elected)
+		readiness.ReconcileCompleted(errors.New("test error"))
+
+		Expect(check(ReadyzBundles)).To(HaveOccurred())
+		Expect(check(ReadyzCrds)).To(Succeed())
+		Expect(check(ReadyzController)).To(Succeed())
+		Expect(check(ReadyzReconcile)).To(Succeed())
+
+		close(elected)
+		Expect(check(ReadyzCrds)).To(HaveOccurred())
+		Expect(check(ReadyzController)).To(HaveOccurred())
+		Expect(check(ReadyzReconcile)).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,8 @@ import (
 	"github.com/go-logr/logr"
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	osconfv1 "github.com/openshift/api/config/v1"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -19,4 +21,16 @@ type Request struct {
 	Instance       *tekton.TektonTasks
 	VersionCache   VersionCache
 	TopologyMode   osconfv1.TopologyMode
+	APIGroups      APIGroups
+	Capabilities   Capabilities
+	// BundleVariantChanged is set when a different bundle variant was deployed before,
+	// so operands prune resources, that are not part of the selected variant.
+	BundleVariantChanged bool
+	// UpdateWindowClosed is set while update windows are defined and none of them is open,
+	// so existing resources of tasks and pipelines are not updated.
+	UpdateWindowClosed bool
+	Recorder           record.EventRecorder
+
+	// runningPipelineRuns are listed once per reconcile, by the first resource, whose update waits for runs
+	runningPipelineRuns []pipeline.PipelineRun
 }
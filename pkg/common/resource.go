@@ -5,10 +5,12 @@ import (
 	"reflect"
 
 	"github.com/go-logr/logr"
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	libhandler "github.com/operator-framework/operator-lib/handler"
-	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -20,6 +22,17 @@ const (
 	OperationResultCreated OperationResult = "created"
 	OperationResultUpdated OperationResult = "updated"
 	OperationResultDeleted OperationResult = "deleted"
+	// OperationResultPruned means that the resource was deleted, because it is no longer desired.
+	OperationResultPruned OperationResult = "pruned"
+	// OperationResultConflict means that the resource exists, is not owned by the operator and was not adopted.
+	OperationResultConflict OperationResult = "conflict"
+	// OperationResultSkipped means that the resource was not reconciled, because its prerequisites failed.
+	OperationResultSkipped OperationResult = "skipped"
+	// OperationResultMissingAPIGroups means that the resource was not deployed, because API groups it depends on are not available.
+	OperationResultMissingAPIGroups OperationResult = "missingAPIGroups"
+	// OperationResultDeferred means that the resource was not updated, because running TaskRuns or PipelineRuns
+	// reference it, or no update window is open.
+	OperationResultDeferred OperationResult = "deferred"
 )
 
 type StatusMessage = *string
@@ -34,6 +47,27 @@ type ReconcileResult struct {
 	Status          ResourceStatus
 	Resource        client.Object
 	OperationResult OperationResult
+	// Diff lists fields changed by an update of an existing resource.
+	Diff []FieldChange
+	// MissingAPIGroups lists API groups, that are required by a resource, that was not deployed.
+	MissingAPIGroups []string
+	// DeferReason describes why an update of the resource was deferred.
+	DeferReason tekton.PendingUpdateReason
+	// Runs lists running TaskRuns and PipelineRuns, that deferred an update of the resource.
+	Runs []tekton.ResourceReference
+	// Found is the resource in the cluster after it was reconciled.
+	Found client.Object
+}
+
+// DesiredStateChanged returns true if the update was caused by a change of the desired state,
+// and not by reverting changes done by other cluster components.
+func (r *ReconcileResult) DesiredStateChanged() bool {
+	for _, change := range r.Diff {
+		if change.Path == "metadata.annotations."+DesiredStateHashAnnotation {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *ReconcileResult) IsSuccess() bool {
@@ -67,7 +101,7 @@ func CollectResourceStatus(request *Request, funcs ...ReconcileFunc) ([]Reconcil
 }
 
 type ResourceUpdateFunc = func(expected, found client.Object)
-type ResourceStatusFunc = func(resource client.Object) ResourceStatus
+type ResourceStatusFunc = func(resource client.Object) (ResourceStatus, error)
 type ResourceSpecGetter = func(resource client.Object) interface{}
 
 type ReconcileOptions struct {
@@ -84,6 +118,8 @@ type ReconcileBuilder interface {
 	UpdateFunc(ResourceUpdateFunc) ReconcileBuilder
 	StatusFunc(ResourceStatusFunc) ReconcileBuilder
 	ImmutableSpec(getter ResourceSpecGetter) ReconcileBuilder
+	DeferSpecUpdate(getter ResourceSpecGetter, runs ResourceRunsFunc) ReconcileBuilder
+	UpdateInWindows() ReconcileBuilder
 
 	Options(options ReconcileOptions) ReconcileBuilder
 
@@ -105,18 +141,19 @@ type reconcileBuilder struct {
 	immutableSpec bool
 	specGetter    ResourceSpecGetter
 
+	deferSpecUpdate bool
+	runsFunc        ResourceRunsFunc
+	updateInWindows bool
+	deferReason     tekton.PendingUpdateReason
+	deferredRuns    []tekton.ResourceReference
+
 	options ReconcileOptions
+
+	diff []FieldChange
 }
 
 var _ ReconcileBuilder = &reconcileBuilder{}
 
-var (
-	TektonOperatorReconcilingProperly = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "tekton_operator_reconciling_properly",
-		Help: "Set to 1 if the reconcile process of all operands completes with no errors, and to 0 otherwise",
-	})
-)
-
 func (r *reconcileBuilder) NamespacedResource(resource client.Object) ReconcileBuilder {
 	r.resource = resource
 	r.isClusterResource = false
@@ -152,12 +189,36 @@ func (r *reconcileBuilder) ImmutableSpec(specGetter ResourceSpecGetter) Reconcil
 	return r
 }
 
+// DeferSpecUpdate defers updates, that change the spec, while runs returned by the runs function reference
+// the resource and the update strategy of the TektonTasks CR waits for runs.
+func (r *reconcileBuilder) DeferSpecUpdate(specGetter ResourceSpecGetter, runsFunc ResourceRunsFunc) ReconcileBuilder {
+	r.deferSpecUpdate = true
+	r.specGetter = specGetter
+	r.runsFunc = runsFunc
+	return r
+}
+
+// UpdateInWindows defers updates of the existing resource, including reverting of changes,
+// while no update window of the TektonTasks CR is open. Missing resources are created at any time.
+func (r *reconcileBuilder) UpdateInWindows() ReconcileBuilder {
+	r.updateInWindows = true
+	return r
+}
+
 func (r *reconcileBuilder) Options(options ReconcileOptions) ReconcileBuilder {
 	r.options = options
 	return r
 }
 
 func (r *reconcileBuilder) Reconcile() (ReconcileResult, error) {
+	span, endSpan := StartSpan(r.request, "ReconcileResource", ObjectAttributes(r.resource)...)
+	result, err := r.reconcile()
+	span.SetAttributes(ResultAttribute.String(string(result.OperationResult)))
+	endSpan(err)
+	return result, err
+}
+
+func (r *reconcileBuilder) reconcile() (ReconcileResult, error) {
 	if r.addLabels {
 		AddAppLabels(r.request.Instance, r.operandName, r.operandComponent, r.resource)
 	}
@@ -167,6 +228,12 @@ func (r *reconcileBuilder) Reconcile() (ReconcileResult, error) {
 		return ReconcileResult{}, err
 	}
 
+	desiredHash, err := DesiredStateHash(r.resource)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	setAnnotation(r.resource, DesiredStateHashAnnotation, desiredHash)
+
 	found := newEmptyResource(r.resource)
 	found.SetName(r.resource.GetName())
 	found.SetNamespace(r.resource.GetNamespace())
@@ -176,15 +243,18 @@ func (r *reconcileBuilder) Reconcile() (ReconcileResult, error) {
 			return nil
 		}
 
+		// Has to be checked before the annotations are updated
+		upToDate := r.request.VersionCache.IsUpToDate(found, desiredHash)
+
 		// We expect users will not add any other owner references,
 		// if that is not correct, this code needs to be changed.
 		found.SetOwnerReferences(r.resource.GetOwnerReferences())
 
 		updateLabels(r.resource, found)
 		updateAnnotations(r.resource, found)
-		if r.options.AlwaysCallUpdateFunc || !r.request.VersionCache.Contains(found) {
-			// The generation was updated by other cluster components,
-			// operator needs to update the resource
+		if r.options.AlwaysCallUpdateFunc || !upToDate {
+			// The desired state changed, or the resource was updated
+			// by other cluster components, operator needs to update the resource
 			r.updateFunc(r.resource, found)
 		}
 		return nil
@@ -195,16 +265,69 @@ func (r *reconcileBuilder) Reconcile() (ReconcileResult, error) {
 		r.request.Logger.Info(fmt.Sprintf("Resource create/update failed: %v", err))
 		return ReconcileResult{}, err
 	}
+	if res == OperationResultConflict {
+		return r.conflictResult(), nil
+	}
+	if res == OperationResultDeferred {
+		return UpdateDeferredResult(r.resource, r.deferReason, r.deferredRuns), nil
+	}
 	if res == OperationResultDeleted || !found.GetDeletionTimestamp().IsZero() {
 		r.request.VersionCache.RemoveObj(found)
-		return ResourceDeletedResult(r.resource, res), nil
+		result := ResourceDeletedResult(r.resource, res)
+		recordOperation(r.request, result)
+		return result, nil
 	}
 
 	r.request.VersionCache.Add(found)
 	logOperation(res, found, r.request.Logger)
 
-	status := r.statusFunc(found)
-	return ReconcileResult{status, r.resource, res}, nil
+	status, err := r.statusFunc(found)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	result := ReconcileResult{
+		Status:          status,
+		Resource:        r.resource,
+		OperationResult: res,
+		Diff:            r.diff,
+		Found:           found,
+	}
+	recordOperation(r.request, result)
+	return result, nil
+}
+
+func (r *reconcileBuilder) conflictResult() ReconcileResult {
+	policy := AdoptionPolicy(r.request)
+	r.request.Logger.Info(fmt.Sprintf("%s resource %s is not owned by the operator, adoption policy: %s",
+		r.resource.GetObjectKind().GroupVersionKind().Kind,
+		r.resource.GetName(),
+		policy))
+
+	result := ReconcileResult{
+		Resource:        r.resource,
+		OperationResult: OperationResultConflict,
+	}
+	RecordEvent(r.request, v1.EventTypeWarning, EventReasonResourceConflict,
+		fmt.Sprintf("%s %s already exists and is not owned by the operator, adoption policy: %s",
+			r.resource.GetObjectKind().GroupVersionKind().Kind,
+			r.resource.GetName(),
+			policy))
+	if policy == tekton.AdoptionPolicyFail {
+		message := "Resource already exists and is not owned by the operator"
+		result.Status = ResourceStatus{
+			NotAvailable: &message,
+			Degraded:     &message,
+		}
+	}
+	return result
+}
+
+// AdoptionPolicy returns the adoption policy of the request, defaulting to Adopt.
+func AdoptionPolicy(request *Request) tekton.AdoptionPolicy {
+	if request.Instance.Spec.AdoptionPolicy == "" {
+		return tekton.AdoptionPolicyAdopt
+	}
+	return request.Instance.Spec.AdoptionPolicy
 }
 
 func CreateOrUpdate(request *Request) ReconcileBuilder {
@@ -217,8 +340,8 @@ func CreateOrUpdate(request *Request) ReconcileBuilder {
 		updateFunc: func(_, _ client.Object) {
 			// Empty function
 		},
-		statusFunc: func(_ client.Object) ResourceStatus {
-			return ResourceStatus{}
+		statusFunc: func(_ client.Object) (ResourceStatus, error) {
+			return ResourceStatus{}, nil
 		},
 
 		immutableSpec: false,
@@ -230,7 +353,7 @@ func CreateOrUpdate(request *Request) ReconcileBuilder {
 
 func Cleanup(request *Request, resource client.Object) (CleanupResult, error) {
 	found := newEmptyResource(resource)
-	err := request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), found)
+	err := getWithFallback(request, client.ObjectKeyFromObject(resource), found)
 	if errors.IsNotFound(err) {
 		return CleanupResult{
 			Resource: resource,
@@ -267,6 +390,10 @@ func Cleanup(request *Request, resource client.Object) (CleanupResult, error) {
 			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s\": %s", resource.GetName(), err))
 			return CleanupResult{}, err
 		}
+		countOperation(resource, MetricOperationDeleted)
+		RecordEvent(request, v1.EventTypeNormal, EventReasonResourceDeleted, fmt.Sprintf("Deleted %s %s",
+			resource.GetObjectKind().GroupVersionKind().Kind,
+			resource.GetName()))
 	}
 
 	return CleanupResult{
@@ -290,7 +417,7 @@ func DeleteAll(request *Request, resources ...client.Object) ([]CleanupResult, e
 // This function was initially copied from controllerutil.CreateOrUpdate
 func (r *reconcileBuilder) createOrUpdateWithImmutableSpec(obj client.Object, f controllerutil.MutateFn) (OperationResult, error) {
 	key := client.ObjectKeyFromObject(obj)
-	if err := r.request.Client.Get(r.request.Context, key, obj); err != nil {
+	if err := getWithFallback(r.request, key, obj); err != nil {
 		if !errors.IsNotFound(err) {
 			return OperationResultNone, err
 		}
@@ -303,6 +430,27 @@ func (r *reconcileBuilder) createOrUpdateWithImmutableSpec(obj client.Object, f
 		return OperationResultCreated, nil
 	}
 
+	owned, err := isResourceOwned(r.request, r.resource.DeepCopyObject().(client.Object), obj)
+	if err != nil {
+		return OperationResultNone, err
+	}
+	if !owned {
+		if AdoptionPolicy(r.request) != tekton.AdoptionPolicyAdopt {
+			return OperationResultConflict, nil
+		}
+		if r.updateInWindows && r.request.UpdateWindowClosed {
+			// Adoption updates the resource, so it waits for the update window as well
+			r.deferReason = tekton.PendingUpdateReasonOutsideUpdateWindow
+			return OperationResultDeferred, nil
+		}
+		r.request.Logger.Info(fmt.Sprintf("Adopting existing %s resource: %s",
+			r.resource.GetObjectKind().GroupVersionKind().Kind,
+			r.resource.GetName()))
+		RecordEvent(r.request, v1.EventTypeNormal, EventReasonResourceAdopted, fmt.Sprintf("Adopted existing %s %s",
+			r.resource.GetObjectKind().GroupVersionKind().Kind,
+			r.resource.GetName()))
+	}
+
 	existing := obj.DeepCopyObject()
 	if err := mutate(f, key, obj); err != nil {
 		return OperationResultNone, err
@@ -312,6 +460,11 @@ func (r *reconcileBuilder) createOrUpdateWithImmutableSpec(obj client.Object, f
 		return OperationResultNone, nil
 	}
 
+	if r.updateInWindows && r.request.UpdateWindowClosed {
+		r.deferReason = tekton.PendingUpdateReasonOutsideUpdateWindow
+		return OperationResultDeferred, nil
+	}
+
 	// If the resource is immutable and specs are not equal, delete it.
 	// It will be recreated in the next iteration.
 	if r.immutableSpec && !equality.Semantic.DeepEqual(r.specGetter(existing.(client.Object)), r.specGetter(obj)) {
@@ -321,12 +474,42 @@ func (r *reconcileBuilder) createOrUpdateWithImmutableSpec(obj client.Object, f
 		return OperationResultDeleted, nil
 	}
 
+	// The whole update is deferred, because the desired state hash would mark the resource as up to date
+	if r.deferSpecUpdate && r.request.Instance.Spec.UpdateStrategy.WaitForRuns &&
+		!equality.Semantic.DeepEqual(r.specGetter(existing.(client.Object)), r.specGetter(obj)) {
+		runs, err := r.runsFunc(r.request, existing.(client.Object))
+		if err != nil {
+			return OperationResultNone, err
+		}
+		if shouldDeferUpdate(r.request, r.resource, runs) {
+			r.deferReason = tekton.PendingUpdateReasonRunsInProgress
+			r.deferredRuns = runs
+			return OperationResultDeferred, nil
+		}
+	}
+
+	diff, err := ComputeDiff(existing.(client.Object), obj)
+	if err != nil {
+		return OperationResultNone, err
+	}
+
 	if err := r.request.Client.Update(r.request.Context, obj); err != nil {
 		return OperationResultNone, err
 	}
+	r.diff = diff
 	return OperationResultUpdated, nil
 }
 
+// getWithFallback reads the object from the cache. The cache only holds resources labeled
+// as managed by the operator, so objects not found there are read using the uncached reader.
+func getWithFallback(request *Request, key client.ObjectKey, obj client.Object) error {
+	err := request.Client.Get(request.Context, key, obj)
+	if !errors.IsNotFound(err) || request.UncachedReader == nil {
+		return err
+	}
+	return request.UncachedReader.Get(request.Context, key, obj)
+}
+
 // This function is a copy of controllerutil.mutate
 func mutate(f controllerutil.MutateFn, key client.ObjectKey, obj client.Object) error {
 	if err := f(); err != nil {
@@ -354,6 +537,12 @@ func setOwner(request *Request, resource client.Object, isClusterRes bool) error
 }
 
 func newEmptyResource(resource client.Object) client.Object {
+	if u, ok := resource.(*unstructured.Unstructured); ok {
+		// Unstructured objects need the kind to be read
+		empty := &unstructured.Unstructured{}
+		empty.SetGroupVersionKind(u.GroupVersionKind())
+		return empty
+	}
 	return reflect.New(reflect.TypeOf(resource).Elem()).Interface().(client.Object)
 }
 
@@ -362,7 +551,10 @@ func updateAnnotations(expected, found client.Object) {
 		found.SetAnnotations(expected.GetAnnotations())
 		return
 	}
-	updateStringMap(expected.GetAnnotations(), found.GetAnnotations())
+	// Unstructured objects return a copy of the map, so it has to be set back
+	annotations := found.GetAnnotations()
+	updateStringMap(expected.GetAnnotations(), annotations)
+	found.SetAnnotations(annotations)
 }
 
 func updateLabels(expected, found client.Object) {
@@ -370,7 +562,18 @@ func updateLabels(expected, found client.Object) {
 		found.SetLabels(expected.GetLabels())
 		return
 	}
-	updateStringMap(expected.GetLabels(), found.GetLabels())
+	labels := found.GetLabels()
+	updateStringMap(expected.GetLabels(), labels)
+	found.SetLabels(labels)
+}
+
+func setAnnotation(obj client.Object, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
 }
 
 func updateStringMap(expected, found map[string]string) {
@@ -396,6 +599,10 @@ func logOperation(result OperationResult, resource client.Object, logger logr.Lo
 		logger.Info(fmt.Sprintf("Deleted %s resource: %s",
 			resource.GetObjectKind().GroupVersionKind().Kind,
 			resource.GetName()))
+	case OperationResultPruned:
+		logger.Info(fmt.Sprintf("Pruned %s resource: %s",
+			resource.GetObjectKind().GroupVersionKind().Kind,
+			resource.GetName()))
 	}
 }
 
@@ -413,9 +620,11 @@ func isResourceOwned(request *Request, expectedObj, foundObj client.Object) (boo
 		return false, err
 	}
 
-	for _, reference := range foundObj.GetOwnerReferences() {
-		if reflect.DeepEqual(reference, expectedObj.GetOwnerReferences()[0]) {
-			return true, nil
+	if len(expectedObj.GetOwnerReferences()) > 0 {
+		for _, reference := range foundObj.GetOwnerReferences() {
+			if reflect.DeepEqual(reference, expectedObj.GetOwnerReferences()[0]) {
+				return true, nil
+			}
 		}
 	}
 	if foundObj.GetAnnotations() != nil {
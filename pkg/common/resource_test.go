@@ -2,6 +2,8 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,6 +13,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -80,6 +83,33 @@ var _ = Describe("Resource", func() {
 			expectEqualResourceExists(newTestResource(namespace), &request)
 		})
 
+		It("should return error, when uncached reader is forbidden", func() {
+			request.UncachedReader = forbiddenReader{}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(errors.IsForbidden(err)).To(BeTrue(), "expected forbidden error, got: %v", err)
+
+			found := newTestResource(namespace)
+			err = request.Client.Get(request.Context, client.ObjectKeyFromObject(found), found)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report changed fields on update", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+			result, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.OperationResult).To(Equal(OperationResultUpdated))
+
+			var paths []string
+			for _, change := range result.Diff {
+				paths = append(paths, change.Path)
+			}
+			Expect(paths).To(ContainElement("spec.ports[0].name"))
+		})
+
 		It("should set owner reference", func() {
 			_, err := createOrUpdateTestResource(&request)
 			Expect(err).ToNot(HaveOccurred())
@@ -156,6 +186,105 @@ var _ = Describe("Resource", func() {
 			expectEqualResourceExists(newTestResource(namespace), &request)
 		})
 
+		It("should set desired state hash annotation", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(newTestResource(namespace)), found)).ToNot(HaveOccurred())
+			Expect(found.GetAnnotations()).To(HaveKey(DesiredStateHashAnnotation))
+		})
+
+		It("should revert changes of resource with matching hash and empty cache", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			resource := newTestResource(namespace)
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).ToNot(HaveOccurred())
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			// Simulates operator restart
+			request.VersionCache = VersionCache{}
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			Expect(res.DesiredStateChanged()).To(BeFalse(), "reverting changes should not be reported as desired state change")
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should not update unchanged resource with matching hash and empty cache", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Simulates operator restart
+			request.VersionCache = VersionCache{}
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultNone))
+		})
+
+		It("should skip unchanged resource with matching hash, when cache is restored from inventory", func() {
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Simulates operator restart
+			request.VersionCache = VersionCache{}
+			request.VersionCache.Restore([]tekton.InventoryEntry{inventoryEntry(res)})
+
+			updateFuncCalled := false
+			res, err = CreateOrUpdate(&request).
+				NamespacedResource(newTestResource(namespace)).
+				UpdateFunc(func(_, _ client.Object) {
+					updateFuncCalled = true
+				}).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultNone))
+			Expect(updateFuncCalled).To(BeFalse())
+		})
+
+		It("should revert changes of resource with matching hash, when cache is restored from inventory", func() {
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			entry := inventoryEntry(res)
+
+			resource := newTestResource(namespace)
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).ToNot(HaveOccurred())
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			// Simulates operator restart
+			request.VersionCache = VersionCache{}
+			request.VersionCache.Restore([]tekton.InventoryEntry{entry})
+
+			res, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should update resource with cached version when desired state changed", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			changed := newTestResource(namespace)
+			changed.Spec.Ports[0].Name = "desired-name"
+			_, err = CreateOrUpdate(&request).
+				NamespacedResource(changed).
+				UpdateFunc(func(expected, found client.Object) {
+					found.(*v1.Service).Spec = expected.(*v1.Service).Spec
+				}).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			expected := newTestResource(namespace)
+			expected.Spec.Ports[0].Name = "desired-name"
+			expectEqualResourceExists(expected, &request)
+		})
+
 		It("should update resource when AlwaysCallUpdateFunc is set", func() {
 			resource := newTestResource(namespace)
 			resource.Spec.Ports[0].Name = "changed-name"
@@ -175,6 +304,62 @@ var _ = Describe("Resource", func() {
 			expectEqualResourceExists(newTestResource(namespace), &request)
 		})
 
+		It("should adopt not owned resource by default", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should not update not owned resource with Skip policy", func() {
+			request.Instance.Spec.AdoptionPolicy = tekton.AdoptionPolicySkip
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultConflict))
+			Expect(res.IsSuccess()).To(BeTrue())
+			expectEqualResourceExists(resource, &request)
+		})
+
+		It("should report degraded not owned resource with Fail policy", func() {
+			request.Instance.Spec.AdoptionPolicy = tekton.AdoptionPolicyFail
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultConflict))
+			Expect(res.Status.Degraded).ToNot(BeNil())
+			expectEqualResourceExists(resource, &request)
+		})
+
+		It("should update owned resource with Fail policy", func() {
+			request.Instance.Spec.AdoptionPolicy = tekton.AdoptionPolicyFail
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			resource := newTestResource(namespace)
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).ToNot(HaveOccurred())
+			request.VersionCache.Add(resource)
+
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).ToNot(HaveOccurred())
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			res, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
 		It("should delete immutable resource on spec update", func() {
 			resource := newTestResource(namespace)
 			resource.Spec.Ports[0].Name = "changed-name"
@@ -218,6 +403,158 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("DeferSpecUpdate", func() {
+		var runs []tekton.ResourceReference
+
+		deferSpecUpdate := func() (ReconcileResult, error) {
+			return CreateOrUpdate(&request).
+				NamespacedResource(newTestResource(namespace)).
+				UpdateFunc(func(expected, found client.Object) {
+					found.(*v1.Service).Spec = expected.(*v1.Service).Spec
+				}).
+				DeferSpecUpdate(func(resource client.Object) interface{} {
+					return resource.(*v1.Service).Spec
+				}, func(*Request, client.Object) ([]tekton.ResourceReference, error) {
+					return runs, nil
+				}).
+				Reconcile()
+		}
+
+		BeforeEach(func() {
+			runs = []tekton.ResourceReference{{Kind: "TaskRun", Namespace: namespace, Name: "test-run"}}
+			request.Instance.Spec.UpdateStrategy.WaitForRuns = true
+
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).To(Succeed())
+		})
+
+		It("should defer spec update, while runs reference the resource", func() {
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultDeferred))
+			Expect(res.Runs).To(Equal(runs))
+			Expect(res.Status.Progressing).ToNot(BeNil())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(newTestResource(namespace)), found)).To(Succeed())
+			Expect(found.Spec.Ports[0].Name).To(Equal("changed-name"))
+			Expect(found.Annotations).ToNot(HaveKey(DesiredStateHashAnnotation))
+		})
+
+		It("should update resource, when no runs reference it", func() {
+			runs = nil
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should update resource, when the update strategy does not wait for runs", func() {
+			request.Instance.Spec.UpdateStrategy.WaitForRuns = false
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should update resource, when the update was deferred longer than max wait", func() {
+			request.Instance.Spec.UpdateStrategy.MaxWait = &metav1.Duration{Duration: time.Minute}
+			request.Instance.Status.PendingUpdates = []tekton.PendingUpdate{{
+				ResourceReference: tekton.ResourceReference{Kind: "Service", Namespace: namespace, Name: "testservice"},
+				Reason:            tekton.PendingUpdateReasonRunsInProgress,
+				Since:             metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+			}}
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should keep deferring update within max wait", func() {
+			request.Instance.Status.PendingUpdates = []tekton.PendingUpdate{{
+				ResourceReference: tekton.ResourceReference{Kind: "Service", Namespace: namespace, Name: "testservice"},
+				Reason:            tekton.PendingUpdateReasonRunsInProgress,
+				Since:             metav1.NewTime(time.Now().Add(-DefaultMaxUpdateWait / 2)),
+			}}
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultDeferred))
+		})
+
+		It("should not defer metadata update", func() {
+			resource := newTestResource(namespace)
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), resource)).To(Succeed())
+			resource.Spec = newTestResource(namespace).Spec
+			resource.Labels["test-label"] = "new-change"
+			Expect(request.Client.Update(request.Context, resource)).To(Succeed())
+
+			res, err := deferSpecUpdate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+	})
+
+	Context("UpdateInWindows", func() {
+		updateInWindows := func() (ReconcileResult, error) {
+			return CreateOrUpdate(&request).
+				NamespacedResource(newTestResource(namespace)).
+				UpdateFunc(func(expected, found client.Object) {
+					found.(*v1.Service).Spec = expected.(*v1.Service).Spec
+				}).
+				UpdateInWindows().
+				Reconcile()
+		}
+
+		BeforeEach(func() {
+			request.UpdateWindowClosed = true
+		})
+
+		It("should create missing resource outside update windows", func() {
+			res, err := updateInWindows()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultCreated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+
+		It("should not update resource outside update windows", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).To(Succeed())
+
+			res, err := updateInWindows()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultDeferred))
+			Expect(res.DeferReason).To(Equal(tekton.PendingUpdateReasonOutsideUpdateWindow))
+			Expect(res.Status.Progressing).ToNot(BeNil())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, client.ObjectKeyFromObject(resource), found)).To(Succeed())
+			Expect(found.Spec.Ports[0].Name).To(Equal("changed-name"))
+		})
+
+		It("should revert changes, when the update window opens", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).To(Succeed())
+			request.VersionCache.Add(resource)
+
+			resource.Spec.Ports[0].Name = "changed-name-2"
+			Expect(request.Client.Update(request.Context, resource)).To(Succeed())
+
+			res, err := updateInWindows()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultDeferred))
+
+			request.UpdateWindowClosed = false
+			res, err = updateInWindows()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.OperationResult).To(Equal(OperationResultUpdated))
+			expectEqualResourceExists(newTestResource(namespace), &request)
+		})
+	})
+
 	Context("Cleanup", func() {
 		It("should succeed Cleanup, if no resource is present", func() {
 			nonexistingResource := newTestResource(namespace)
@@ -337,6 +674,33 @@ func expectEqualResourceExists(resource client.Object, request *Request) {
 	resource.SetGeneration(found.GetGeneration())
 	resource.SetResourceVersion(found.GetResourceVersion())
 	resource.SetOwnerReferences(found.GetOwnerReferences())
+	if hash, ok := found.GetAnnotations()[DesiredStateHashAnnotation]; ok {
+		setAnnotation(resource, DesiredStateHashAnnotation, hash)
+	}
 
 	ExpectWithOffset(1, found).To(Equal(resource))
 }
+
+func inventoryEntry(result ReconcileResult) tekton.InventoryEntry {
+	return tekton.InventoryEntry{
+		ResourceReference: tekton.ResourceReference{
+			Kind:      ObjectKind(result.Resource),
+			Namespace: result.Resource.GetNamespace(),
+			Name:      result.Resource.GetName(),
+		},
+		UID:             result.Found.GetUID(),
+		ResourceVersion: result.Found.GetResourceVersion(),
+		Generation:      result.Found.GetGeneration(),
+	}
+}
+
+// forbiddenReader simulates an uncached reader, that is not allowed to read resources by RBAC.
+type forbiddenReader struct{}
+
+func (forbiddenReader) Get(_ context.Context, key client.ObjectKey, obj client.Object) error {
+	return errors.NewForbidden(schema.GroupResource{Resource: ObjectKind(obj)}, key.Name, fmt.Errorf("access denied"))
+}
+
+func (forbiddenReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return errors.NewForbidden(schema.GroupResource{}, "", fmt.Errorf("access denied"))
+}
@@ -5,6 +5,7 @@ import (
 
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -20,4 +21,5 @@ func init() {
 	utilruntime.Must(extv1.AddToScheme(Scheme))
 	utilruntime.Must(tekton.AddToScheme(Scheme))
 	utilruntime.Must(openshiftconfigv1.AddToScheme(Scheme))
+	utilruntime.Must(operatorsv2.AddToScheme(Scheme))
 }
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get
+
+// ServiceAccountSecretsGracePeriod is the time given to cluster components to add
+// token or pull secrets to a new ServiceAccount. Clusters that do not generate
+// these secrets report the ServiceAccount as ready after this period.
+const ServiceAccountSecretsGracePeriod = 2 * time.Minute
+
+// ProgressingStatus returns a status of a resource, that is expected to become ready without operator action.
+func ProgressingStatus(message string) ResourceStatus {
+	return ResourceStatus{
+		Progressing:  &message,
+		NotAvailable: &message,
+	}
+}
+
+// DegradedStatus returns a status of a resource, that cannot become ready.
+func DegradedStatus(message string) ResourceStatus {
+	return ResourceStatus{
+		NotAvailable: &message,
+		Degraded:     &message,
+	}
+}
+
+// ResourceExists checks if the resource exists. The cache is checked first,
+// resources not found in the cache are read using the uncached reader, if the request has one.
+func ResourceExists(request *Request, key client.ObjectKey, obj client.Object) (bool, error) {
+	err := getWithFallback(request, key, obj)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RoleBindingStatus returns a StatusFunc, that checks if the role referenced by a RoleBinding exists.
+// Errors other than NotFound are returned, because they are failures of the operator, not of the RoleBinding.
+func RoleBindingStatus(request *Request) ResourceStatusFunc {
+	return func(resource client.Object) (ResourceStatus, error) {
+		roleBinding := resource.(*rbac.RoleBinding)
+
+		var role client.Object
+		key := client.ObjectKey{Name: roleBinding.RoleRef.Name}
+		switch roleBinding.RoleRef.Kind {
+		case "ClusterRole":
+			role = &rbac.ClusterRole{}
+		case "Role":
+			role = &rbac.Role{}
+			key.Namespace = roleBinding.Namespace
+		default:
+			return DegradedStatus(fmt.Sprintf("Unknown roleRef kind %s", roleBinding.RoleRef.Kind)), nil
+		}
+
+		exists, err := existsUncachedForNamespaced(request, key, role)
+		if err != nil {
+			return ResourceStatus{}, fmt.Errorf("failed to get %s %s: %w", roleBinding.RoleRef.Kind, roleBinding.RoleRef.Name, err)
+		}
+		if !exists {
+			return DegradedStatus(fmt.Sprintf("Referenced %s %s does not exist", roleBinding.RoleRef.Kind, roleBinding.RoleRef.Name)), nil
+		}
+		return ResourceStatus{}, nil
+	}
+}
+
+// ServiceAccountStatus returns a StatusFunc, that checks if a ServiceAccount has token or pull secrets.
+func ServiceAccountStatus() ResourceStatusFunc {
+	return func(resource client.Object) (ResourceStatus, error) {
+		serviceAccount := resource.(*v1.ServiceAccount)
+		if len(serviceAccount.Secrets) > 0 || len(serviceAccount.ImagePullSecrets) > 0 {
+			return ResourceStatus{}, nil
+		}
+		if time.Since(serviceAccount.CreationTimestamp.Time) > ServiceAccountSecretsGracePeriod {
+			return ResourceStatus{}, nil
+		}
+		return ProgressingStatus("Waiting for token or pull secrets"), nil
+	}
+}
+
+// Namespaced Roles are not watched by the operator, so they are read without the cache.
+func existsUncachedForNamespaced(request *Request, key client.ObjectKey, obj client.Object) (bool, error) {
+	if key.Namespace == "" || request.UncachedReader == nil {
+		return ResourceExists(request, key, obj)
+	}
+	err := request.UncachedReader.Get(request.Context, key, obj)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Status", func() {
+	var request Request
+
+	BeforeEach(func() {
+		request = Request{
+			Client:  fake.NewFakeClientWithScheme(scheme.Scheme),
+			Context: context.Background(),
+			Logger:  log,
+		}
+	})
+
+	Context("RoleBinding", func() {
+		var roleBinding *rbac.RoleBinding
+
+		BeforeEach(func() {
+			roleBinding = &rbac.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rb", Namespace: namespace},
+				RoleRef:    rbac.RoleRef{Kind: "ClusterRole", Name: "test-role"},
+			}
+		})
+
+		It("should be degraded when referenced role does not exist", func() {
+			status, err := RoleBindingStatus(&request)(roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Degraded).ToNot(BeNil())
+			Expect(*status.Degraded).To(ContainSubstring("ClusterRole test-role"))
+		})
+
+		It("should return error when reading referenced role is forbidden", func() {
+			request.UncachedReader = forbiddenReader{}
+
+			_, err := RoleBindingStatus(&request)(roleBinding)
+			Expect(errors.IsForbidden(err)).To(BeTrue(), "expected forbidden error, got: %v", err)
+		})
+
+		It("should be healthy when referenced role exists", func() {
+			role := &rbac.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "test-role"}}
+			Expect(request.Client.Create(request.Context, role)).To(Succeed())
+
+			Expect(RoleBindingStatus(&request)(roleBinding)).To(Equal(ResourceStatus{}))
+		})
+
+		It("should find role using uncached reader", func() {
+			role := &rbac.Role{ObjectMeta: metav1.ObjectMeta{Name: "test-role", Namespace: namespace}}
+			request.UncachedReader = fake.NewFakeClientWithScheme(scheme.Scheme, role)
+			roleBinding.RoleRef.Kind = "Role"
+
+			Expect(RoleBindingStatus(&request)(roleBinding)).To(Equal(ResourceStatus{}))
+		})
+
+		It("should find cluster role missing in the cache using uncached reader", func() {
+			role := &rbac.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "test-role"}}
+			request.UncachedReader = fake.NewFakeClientWithScheme(scheme.Scheme, role)
+
+			Expect(RoleBindingStatus(&request)(roleBinding)).To(Equal(ResourceStatus{}))
+		})
+	})
+
+	Context("ServiceAccount", func() {
+		It("should be progressing without secrets", func() {
+			serviceAccount := &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sa", CreationTimestamp: metav1.Now()},
+			}
+			status, err := ServiceAccountStatus()(serviceAccount)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Progressing).ToNot(BeNil())
+			Expect(status.Degraded).To(BeNil())
+		})
+
+		It("should be healthy with pull secrets", func() {
+			serviceAccount := &v1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "test-sa", CreationTimestamp: metav1.Now()},
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "test-sa-dockercfg"}},
+			}
+			Expect(ServiceAccountStatus()(serviceAccount)).To(Equal(ResourceStatus{}))
+		})
+
+		It("should be healthy without secrets after grace period", func() {
+			serviceAccount := &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-sa",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * ServiceAccountSecretsGracePeriod)),
+				},
+			}
+			Expect(ServiceAccountStatus()(serviceAccount)).To(Equal(ResourceStatus{}))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package common
+
+import (
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	osconfv1 "github.com/openshift/api/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get
+
+const infrastructureName = "cluster"
+
+// GetTopologyMode returns the topology of nodes running workloads, unless it is overridden in the TektonTasks CR.
+// Clusters without the OpenShift Infrastructure resource are treated as highly available.
+func GetTopologyMode(request *Request) (osconfv1.TopologyMode, error) {
+	if override := request.Instance.Spec.TopologyMode; override != "" {
+		return osconfv1.TopologyMode(override), nil
+	}
+
+	infrastructure := &osconfv1.Infrastructure{}
+	err := request.UncachedReader.Get(request.Context, client.ObjectKey{Name: infrastructureName}, infrastructure)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return osconfv1.HighlyAvailableTopologyMode, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The control plane topology is not relevant, because pipelines run only on infrastructure and worker nodes
+	if infrastructure.Status.InfrastructureTopology == "" {
+		return osconfv1.HighlyAvailableTopologyMode, nil
+	}
+	return infrastructure.Status.InfrastructureTopology, nil
+}
+
+// IsSingleReplica returns true, when workloads run on a single node.
+func IsSingleReplica(request *Request) bool {
+	return request.TopologyMode == osconfv1.SingleReplicaTopologyMode
+}
+
+// TopologyProfile returns the name of the topology profile, that resources are adapted for.
+func TopologyProfile(request *Request) string {
+	if IsSingleReplica(request) {
+		return string(tekton.TopologyModeSingleReplica)
+	}
+	return string(tekton.TopologyModeHighlyAvailable)
+}
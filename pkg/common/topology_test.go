@@ -0,0 +1,56 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	osconfv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Topology", func() {
+	var request Request
+
+	BeforeEach(func() {
+		request = Request{
+			UncachedReader: fake.NewFakeClientWithScheme(Scheme),
+			Context:        context.Background(),
+			Instance:       &tekton.TektonTasks{},
+		}
+	})
+
+	infrastructure := func(topology osconfv1.TopologyMode) *osconfv1.Infrastructure {
+		return &osconfv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: infrastructureName},
+			Status: osconfv1.InfrastructureStatus{
+				ControlPlaneTopology:   osconfv1.ExternalTopologyMode,
+				InfrastructureTopology: topology,
+			},
+		}
+	}
+
+	It("should be highly available without Infrastructure", func() {
+		Expect(GetTopologyMode(&request)).To(Equal(osconfv1.HighlyAvailableTopologyMode))
+	})
+
+	It("should read infrastructure topology", func() {
+		request.UncachedReader = fake.NewFakeClientWithScheme(Scheme, infrastructure(osconfv1.SingleReplicaTopologyMode))
+		Expect(GetTopologyMode(&request)).To(Equal(osconfv1.SingleReplicaTopologyMode))
+	})
+
+	It("should prefer override from TektonTasks CR", func() {
+		request.UncachedReader = fake.NewFakeClientWithScheme(Scheme, infrastructure(osconfv1.SingleReplicaTopologyMode))
+		request.Instance.Spec.TopologyMode = tekton.TopologyModeHighlyAvailable
+		Expect(GetTopologyMode(&request)).To(Equal(osconfv1.HighlyAvailableTopologyMode))
+	})
+
+	It("should report topology profile", func() {
+		Expect(TopologyProfile(&request)).To(Equal(string(tekton.TopologyModeHighlyAvailable)))
+		request.TopologyMode = osconfv1.SingleReplicaTopologyMode
+		Expect(TopologyProfile(&request)).To(Equal(string(tekton.TopologyModeSingleReplica)))
+	})
+})
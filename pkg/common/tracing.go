@@ -0,0 +1,215 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const tracerName = "github.com/kubevirt/tekton-tasks-operator"
+
+// Attributes of spans, that describe a Kubernetes object
+const (
+	KindAttribute      = attribute.Key("k8s.object.kind")
+	NamespaceAttribute = attribute.Key("k8s.namespace.name")
+	NameAttribute      = attribute.Key("k8s.object.name")
+	OperandAttribute   = attribute.Key("tekton-tasks.operand")
+	ResultAttribute    = attribute.Key("tekton-tasks.operation.result")
+	MigrationAttribute = attribute.Key("tekton-tasks.migration")
+)
+
+// SetupTracing configures the global tracer provider to export spans over OTLP/HTTP to the endpoint.
+// The returned function flushes and stops the exporter.
+func SetupTracing(ctx context.Context, endpoint string, insecure bool, version string) (func(context.Context) error, error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(AppKubernetesManagedByValue),
+		semconv.ServiceVersionKey.String(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// StartContextSpan starts a span, that is a child of the span in the context.
+func StartContextSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartSpan starts a span, that is a child of the span in the request context.
+// The request context is replaced by the context of the new span, until the returned function is called.
+func StartSpan(request *Request, name string, attrs ...attribute.KeyValue) (trace.Span, func(error)) {
+	parent := request.Context
+	ctx, span := StartContextSpan(parent, name, attrs...)
+	request.Context = ctx
+	return span, func(err error) {
+		EndSpan(span, err)
+		request.Context = parent
+	}
+}
+
+// EndSpan records the error, if any, and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ObjectAttributes returns span attributes identifying the object.
+func ObjectAttributes(obj client.Object) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		KindAttribute.String(ObjectKind(obj)),
+		NamespaceAttribute.String(obj.GetNamespace()),
+		NameAttribute.String(obj.GetName()),
+	}
+}
+
+// NewTracingClient returns a client, that creates a span for each API call.
+func NewTracingClient(cli client.Client) client.Client {
+	return &tracingClient{Client: cli}
+}
+
+// NewTracingReader returns a reader, that creates a span for each API call.
+func NewTracingReader(reader client.Reader) client.Reader {
+	return &tracingReader{reader: reader}
+}
+
+type tracingReader struct {
+	reader client.Reader
+}
+
+func (t *tracingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	ctx, span := startCallSpan(ctx, "Get", obj, key.Namespace, key.Name)
+	err := t.reader.Get(ctx, key, obj)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, span := startCallSpan(ctx, "List", list, listNamespace(opts), "")
+	err := t.reader.List(ctx, list, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+type tracingClient struct {
+	client.Client
+}
+
+func (t *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	ctx, span := startCallSpan(ctx, "Get", obj, key.Namespace, key.Name)
+	err := t.Client.Get(ctx, key, obj)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, span := startCallSpan(ctx, "List", list, listNamespace(opts), "")
+	err := t.Client.List(ctx, list, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, span := startCallSpan(ctx, "Create", obj, obj.GetNamespace(), obj.GetName())
+	err := t.Client.Create(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, span := startCallSpan(ctx, "Delete", obj, obj.GetNamespace(), obj.GetName())
+	err := t.Client.Delete(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, span := startCallSpan(ctx, "Update", obj, obj.GetNamespace(), obj.GetName())
+	err := t.Client.Update(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, span := startCallSpan(ctx, "Patch", obj, obj.GetNamespace(), obj.GetName())
+	err := t.Client.Patch(ctx, obj, patch, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	ctx, span := startCallSpan(ctx, "DeleteAllOf", obj, obj.GetNamespace(), "")
+	err := t.Client.DeleteAllOf(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingClient) Status() client.StatusWriter {
+	return &tracingStatusWriter{StatusWriter: t.Client.Status()}
+}
+
+type tracingStatusWriter struct {
+	client.StatusWriter
+}
+
+func (t *tracingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, span := startCallSpan(ctx, "UpdateStatus", obj, obj.GetNamespace(), obj.GetName())
+	err := t.StatusWriter.Update(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, span := startCallSpan(ctx, "PatchStatus", obj, obj.GetNamespace(), obj.GetName())
+	err := t.StatusWriter.Patch(ctx, obj, patch, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func startCallSpan(ctx context.Context, verb string, obj runtime.Object, namespace, name string) (context.Context, trace.Span) {
+	kind := ObjectKind(obj)
+	attrs := []attribute.KeyValue{KindAttribute.String(kind)}
+	if namespace != "" {
+		attrs = append(attrs, NamespaceAttribute.String(namespace))
+	}
+	if name != "" {
+		attrs = append(attrs, NameAttribute.String(name))
+	}
+	return StartContextSpan(ctx, fmt.Sprintf("%s %s", verb, kind), attrs...)
+}
+
+func listNamespace(opts []client.ListOption) string {
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	return listOptions.Namespace
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		request       Request
+		spanRecorder  *tracetest.SpanRecorder
+		savedProvider trace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		s := scheme.Scheme
+		Expect(tekton.AddToScheme(s)).ToNot(HaveOccurred())
+
+		savedProvider = otel.GetTracerProvider()
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+
+		request = Request{
+			Client:  NewTracingClient(fake.NewFakeClientWithScheme(s)),
+			Context: context.Background(),
+			Instance: &tekton.TektonTasks{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       tektonResourceKind,
+					APIVersion: tekton.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			},
+			Logger:       log,
+			VersionCache: VersionCache{},
+		}
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(savedProvider)
+	})
+
+	It("should trace resource reconciliation with client calls as children", func() {
+		ctx := request.Context
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(request.Context).To(Equal(ctx), "request context should be restored")
+
+		spans := spanRecorder.Ended()
+		names := make([]string, 0, len(spans))
+		for _, span := range spans {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(ContainElements("Get Service", "Create Service", "ReconcileResource"))
+
+		reconcileSpan := spans[len(spans)-1]
+		Expect(reconcileSpan.Name()).To(Equal("ReconcileResource"))
+		Expect(reconcileSpan.Attributes()).To(ContainElements(
+			KindAttribute.String("Service"),
+			NamespaceAttribute.String(namespace),
+			ResultAttribute.String(string(OperationResultCreated)),
+		))
+		for _, span := range spans[:len(spans)-1] {
+			Expect(span.Parent().SpanID()).To(Equal(reconcileSpan.SpanContext().SpanID()))
+		}
+	})
+})
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+)
+
+// UpdateWindowsState describes the update windows of the TektonTasks CR at a point in time.
+type UpdateWindowsState struct {
+	// Open is true, when no windows are defined, or any of them is open.
+	Open bool
+	// NextStart is the time, when the next window opens. It is nil, when a window is open.
+	NextStart *time.Time
+}
+
+// CheckUpdateWindows returns the state of update windows at the given time.
+// A window is open, if its schedule fired within the window duration before the time.
+func CheckUpdateWindows(windows []tekton.UpdateWindow, now time.Time) (UpdateWindowsState, error) {
+	if len(windows) == 0 {
+		return UpdateWindowsState{Open: true}, nil
+	}
+
+	now = now.UTC()
+	var nextStart *time.Time
+	for _, window := range windows {
+		schedule, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return UpdateWindowsState{}, fmt.Errorf("invalid schedule %q of update window: %w", window.Schedule, err)
+		}
+		if window.Duration.Duration <= 0 {
+			return UpdateWindowsState{}, fmt.Errorf("duration of update window %q has to be positive", window.Schedule)
+		}
+
+		if lastStart := schedule.Next(now.Add(-window.Duration.Duration)); !lastStart.After(now) {
+			return UpdateWindowsState{Open: true}, nil
+		}
+		next := schedule.Next(now)
+		if !next.IsZero() && (nextStart == nil || next.Before(*nextStart)) {
+			nextStart = &next
+		}
+	}
+	return UpdateWindowsState{NextStart: nextStart}, nil
+}
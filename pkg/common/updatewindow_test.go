@@ -0,0 +1,74 @@
+package common
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Update windows", func() {
+	// Saturday
+	now := time.Date(2022, time.March, 5, 3, 0, 0, 0, time.UTC)
+
+	window := func(schedule string, duration time.Duration) tekton.UpdateWindow {
+		return tekton.UpdateWindow{Schedule: schedule, Duration: metav1.Duration{Duration: duration}}
+	}
+
+	It("should be open, when no windows are defined", func() {
+		state, err := CheckUpdateWindows(nil, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeTrue())
+		Expect(state.NextStart).To(BeNil())
+	})
+
+	It("should be open within the window duration", func() {
+		state, err := CheckUpdateWindows([]tekton.UpdateWindow{window("0 2 * * 6", 4*time.Hour)}, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeTrue())
+		Expect(state.NextStart).To(BeNil())
+	})
+
+	It("should be closed after the window duration and report the next start", func() {
+		state, err := CheckUpdateWindows([]tekton.UpdateWindow{window("0 2 * * 6", time.Hour)}, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeFalse())
+		Expect(*state.NextStart).To(Equal(time.Date(2022, time.March, 12, 2, 0, 0, 0, time.UTC)))
+	})
+
+	It("should report the earliest start of multiple windows", func() {
+		state, err := CheckUpdateWindows([]tekton.UpdateWindow{
+			window("0 2 * * 6", time.Hour),
+			window("30 22 * * *", time.Hour),
+		}, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeFalse())
+		Expect(*state.NextStart).To(Equal(time.Date(2022, time.March, 5, 22, 30, 0, 0, time.UTC)))
+	})
+
+	It("should be open, when any window is open", func() {
+		state, err := CheckUpdateWindows([]tekton.UpdateWindow{
+			window("30 22 * * *", time.Hour),
+			window("0 * * * *", 30*time.Minute),
+		}, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeTrue())
+	})
+
+	It("should evaluate schedules in the time zone of the schedule", func() {
+		// 03:00 UTC is 04:00 in Prague
+		state, err := CheckUpdateWindows([]tekton.UpdateWindow{window("CRON_TZ=Europe/Prague 0 4 * * *", time.Minute)}, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Open).To(BeTrue())
+	})
+
+	It("should reject invalid windows", func() {
+		_, err := CheckUpdateWindows([]tekton.UpdateWindow{window("every saturday", time.Hour)}, now)
+		Expect(err).To(MatchError(ContainSubstring("invalid schedule")))
+		_, err = CheckUpdateWindows([]tekton.UpdateWindow{window("0 2 * * 6", 0)}, now)
+		Expect(err).To(MatchError(ContainSubstring("has to be positive")))
+	})
+})
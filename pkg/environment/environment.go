@@ -33,6 +33,10 @@ const (
 	ModifyVMTemplateImageKey  = "MODIFY_VM_TEMPLATE_IMG"
 	WaitForVMISTatusImageKey  = "WAIT_FOR_VMI_STATUS_IMG"
 	GenerateSSHKeysImageKey   = "GENERATE_SSH_KEYS_IMG"
+	RequeueBaseDelayKey       = "REQUEUE_BASE_DELAY"
+	RequeueMaxDelayKey        = "REQUEUE_MAX_DELAY"
+	ResyncPeriodKey           = "RESYNC_PERIOD"
+	ReconcileWindowKey        = "READINESS_RECONCILE_WINDOW"
 
 	DefaultWaitForVMIStatusIMG  = "quay.io/kubevirt/tekton-task-wait-for-vmi-status:" + operands.TektonTasksVersion
 	DeafultModifyVMTemplateIMG  = "quay.io/kubevirt/tekton-task-modify-vm-template:" + operands.TektonTasksVersion
@@ -45,6 +49,11 @@ const (
 	GenerateSSHKeysIMG          = "quay.io/kubevirt/tekton-task-generate-ssh-keys:" + operands.TektonTasksVersion
 
 	defaultOperatorVersion = "devel"
+
+	DefaultRequeueBaseDelay = 1 * time.Second
+	DefaultRequeueMaxDelay  = 5 * time.Minute
+	DefaultResyncPeriod     = 10 * time.Minute
+	DefaultReconcileWindow  = 30 * time.Minute
 )
 
 // GetSSHKeysStatusImage returns generate-ssh-keys task image url
@@ -97,6 +106,34 @@ func GetOperatorNamespace() string {
 	return EnvOrDefault(OperatorNamespaceKey, "kubevirt")
 }
 
+// GetRequeueBaseDelay returns the initial delay of requeues while resources are not ready
+func GetRequeueBaseDelay() time.Duration {
+	return durationOrDefault(RequeueBaseDelayKey, DefaultRequeueBaseDelay)
+}
+
+// GetRequeueMaxDelay returns the maximum delay of requeues while resources are not ready
+func GetRequeueMaxDelay() time.Duration {
+	return durationOrDefault(RequeueMaxDelayKey, DefaultRequeueMaxDelay)
+}
+
+// GetResyncPeriod returns the period of reconciliation without any watch event, 0 disables it
+func GetResyncPeriod() time.Duration {
+	return durationOrDefault(ResyncPeriodKey, DefaultResyncPeriod)
+}
+
+// GetReconcileWindow returns the maximum age of the last successful reconcile of a ready operator, 0 disables the check
+func GetReconcileWindow() time.Duration {
+	return durationOrDefault(ReconcileWindowKey, DefaultReconcileWindow)
+}
+
+func durationOrDefault(varName string, defVal time.Duration) time.Duration {
+	duration, err := LookupAsDuration(varName)
+	if err != nil || duration < 0 {
+		return defVal
+	}
+	return duration
+}
+
 func LookupAsDuration(varName string) (time.Duration, error) {
 	duration := time.Duration(0)
 	varValue, ok := os.LookupEnv(varName)
@@ -3,6 +3,7 @@ package environment
 import (
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -23,6 +24,30 @@ var _ = Describe("environments", func() {
 		Expect(res).To(Equal(defaultOperatorVersion), "OPERATOR_VERSION should equal")
 	})
 
+	It("should return correct value for RESYNC_PERIOD when variable is set", func() {
+		os.Setenv(ResyncPeriodKey, "30m")
+		Expect(GetResyncPeriod()).To(Equal(30*time.Minute), "RESYNC_PERIOD should equal")
+		os.Unsetenv(ResyncPeriodKey)
+	})
+
+	It("should return default value for RESYNC_PERIOD when variable is invalid", func() {
+		os.Setenv(ResyncPeriodKey, "invalid")
+		Expect(GetResyncPeriod()).To(Equal(DefaultResyncPeriod), "RESYNC_PERIOD should equal")
+		os.Unsetenv(ResyncPeriodKey)
+	})
+
+	It("should return correct value for READINESS_RECONCILE_WINDOW", func() {
+		Expect(GetReconcileWindow()).To(Equal(DefaultReconcileWindow), "READINESS_RECONCILE_WINDOW should equal")
+		os.Setenv(ReconcileWindowKey, "1h")
+		Expect(GetReconcileWindow()).To(Equal(time.Hour), "READINESS_RECONCILE_WINDOW should equal")
+		os.Unsetenv(ReconcileWindowKey)
+	})
+
+	It("should return default values for requeue delays when variables are not set", func() {
+		Expect(GetRequeueBaseDelay()).To(Equal(DefaultRequeueBaseDelay), "REQUEUE_BASE_DELAY should equal")
+		Expect(GetRequeueMaxDelay()).To(Equal(DefaultRequeueMaxDelay), "REQUEUE_MAX_DELAY should equal")
+	})
+
 	It("should return correct value for CLEANUP_VM_IMG when variable is set", func() {
 		os.Setenv(CleanupVMImageKey, testURL)
 		res := GetCleanupVMImage()
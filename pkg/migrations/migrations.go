@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+)
+
+// Migration changes resources deployed by older versions of the operator,
+// so they can be reconciled by the current version. E.g. it renames resources,
+// moves labels, rewrites owner annotations or removes retired tasks.
+type Migration struct {
+	// Name identifies the migration in status.
+	Name string
+	// FromVersion is the oldest operator version, that the migration upgrades from. Empty means all older versions.
+	FromVersion string
+	// ToVersion is the operator version, that introduced the change.
+	ToVersion string
+	// Migrate applies the migration. It is retried until it succeeds, so it has to be idempotent.
+	Migrate func(*common.Request) error
+
+	fromVersion *semver.Version
+	toVersion   semver.Version
+}
+
+// Registry holds migrations ordered by the versions they upgrade to.
+type Registry struct {
+	migrations []Migration
+}
+
+func NewRegistry(migrations ...Migration) (*Registry, error) {
+	registry := &Registry{}
+	for _, migration := range migrations {
+		if err := registry.Register(migration); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register adds a migration. Migrations with the same target version run in the order they were registered.
+func (r *Registry) Register(migration Migration) error {
+	for _, registered := range r.migrations {
+		if registered.Name == migration.Name {
+			return fmt.Errorf("migration %s is already registered", migration.Name)
+		}
+	}
+
+	toVersion, err := semver.ParseTolerant(migration.ToVersion)
+	if err != nil {
+		return fmt.Errorf("invalid target version of migration %s: %w", migration.Name, err)
+	}
+	migration.toVersion = toVersion
+
+	if migration.FromVersion != "" {
+		fromVersion, err := semver.ParseTolerant(migration.FromVersion)
+		if err != nil {
+			return fmt.Errorf("invalid source version of migration %s: %w", migration.Name, err)
+		}
+		migration.fromVersion = &fromVersion
+	}
+
+	r.migrations = append(r.migrations, migration)
+	sort.SliceStable(r.migrations, func(i, j int) bool {
+		return r.migrations[i].toVersion.LT(r.migrations[j].toVersion)
+	})
+	return nil
+}
+
+// RecordPending records migrations, that are needed to upgrade from the version observed in status
+// to the operator version, and that have not completed yet, in status of the TektonTasks CR.
+// It returns true, if there are any such migrations.
+func (r *Registry) RecordPending(request *common.Request) bool {
+	fromVersion := request.Instance.Status.ObservedVersion
+	toVersion := environment.GetOperatorVersion()
+
+	// Incomplete migrations, that were removed from the operator, would never complete
+	recorded := request.Instance.Status.Migrations[:0]
+	for _, status := range request.Instance.Status.Migrations {
+		if status.Completed || r.find(status.Name) != nil {
+			recorded = append(recorded, status)
+		}
+	}
+	request.Instance.Status.Migrations = recorded
+
+	pending := false
+	for _, migration := range r.migrations {
+		status := findStatus(request.Instance, migration.Name)
+		if status != nil {
+			pending = pending || !status.Completed
+			continue
+		}
+		if !migration.appliesTo(fromVersion, toVersion) {
+			continue
+		}
+		request.Instance.Status.Migrations = append(request.Instance.Status.Migrations, tekton.MigrationStatus{
+			Name:        migration.Name,
+			FromVersion: fromVersion,
+			ToVersion:   migration.ToVersion,
+		})
+		pending = true
+	}
+	return pending
+}
+
+// Run applies pending migrations in the order of their target versions. It stops at the first failure,
+// so later migrations can rely on earlier ones. The progress is recorded in status of the TektonTasks CR.
+func (r *Registry) Run(request *common.Request) error {
+	r.RecordPending(request)
+
+	for _, migration := range r.migrations {
+		status := findStatus(request.Instance, migration.Name)
+		if status == nil || status.Completed {
+			continue
+		}
+
+		request.Logger.Info("Running upgrade migration", "migration", migration.Name, "from", status.FromVersion, "to", migration.ToVersion)
+		_, endSpan := common.StartSpan(request, "Migrate", common.MigrationAttribute.String(migration.Name))
+		err := migration.Migrate(request)
+		endSpan(err)
+
+		// The migration may have updated the TektonTasks CR, which replaces its status
+		status = findStatus(request.Instance, migration.Name)
+		if err != nil {
+			status.Error = err.Error()
+			return fmt.Errorf("upgrade migration %s failed: %w", migration.Name, err)
+		}
+
+		now := metav1.Now()
+		status.Completed = true
+		status.CompletionTime = &now
+		status.Error = ""
+		common.RecordEvent(request, v1.EventTypeNormal, common.EventReasonMigrationCompleted,
+			fmt.Sprintf("Upgrade migration %s completed", migration.Name))
+	}
+	return nil
+}
+
+// appliesTo returns true, if the migration is needed to upgrade between the versions.
+// An empty previous version means a fresh install, which has nothing to migrate.
+// Versions, that are not semantic versions, e.g. development builds, are treated as unknown.
+// An unknown previous version may be older than any migration, so all migrations are applied.
+func (m *Migration) appliesTo(fromVersion, toVersion string) bool {
+	if fromVersion == "" {
+		return false
+	}
+	if current, err := semver.ParseTolerant(toVersion); err == nil && current.LT(m.toVersion) {
+		return false
+	}
+
+	previous, err := semver.ParseTolerant(fromVersion)
+	if err != nil {
+		return true
+	}
+	if previous.GTE(m.toVersion) {
+		return false
+	}
+	return m.fromVersion == nil || previous.GTE(*m.fromVersion)
+}
+
+func (r *Registry) find(name string) *Migration {
+	for i := range r.migrations {
+		if r.migrations[i].Name == name {
+			return &r.migrations[i]
+		}
+	}
+	return nil
+}
+
+func findStatus(instance *tekton.TektonTasks, name string) *tekton.MigrationStatus {
+	for i := range instance.Status.Migrations {
+		if instance.Status.Migrations[i].Name == name {
+			return &instance.Status.Migrations[i]
+		}
+	}
+	return nil
+}
+
+// IsUpgrading returns true, when resources were last deployed by a different version of the operator.
+func IsUpgrading(request *common.Request) bool {
+	return request.Instance.Status.ObservedVersion != environment.GetOperatorVersion()
+}
@@ -0,0 +1,195 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+)
+
+var _ = Describe("Migrations", func() {
+	var (
+		request *common.Request
+		applied []string
+	)
+
+	migration := func(name, fromVersion, toVersion string) Migration {
+		return Migration{
+			Name:        name,
+			FromVersion: fromVersion,
+			ToVersion:   toVersion,
+			Migrate: func(*common.Request) error {
+				applied = append(applied, name)
+				return nil
+			},
+		}
+	}
+
+	newRegistry := func(migrations ...Migration) *Registry {
+		registry, err := NewRegistry(migrations...)
+		Expect(err).ToNot(HaveOccurred())
+		return registry
+	}
+
+	BeforeEach(func() {
+		os.Setenv(environment.OperatorVersionKey, "v0.3.0")
+		applied = nil
+		request = &common.Request{
+			Context:  context.Background(),
+			Logger:   logf.Log.WithName("migrations"),
+			Instance: &tekton.TektonTasks{},
+		}
+		request.Instance.Status.ObservedVersion = "v0.1.0"
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(environment.OperatorVersionKey)
+	})
+
+	It("should run migrations ordered by target version and record them in status", func() {
+		registry := newRegistry(
+			migration("second", "", "0.3.0"),
+			migration("first", "", "0.2.0"),
+		)
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(Equal([]string{"first", "second"}))
+
+		Expect(request.Instance.Status.Migrations).To(HaveLen(2))
+		for _, status := range request.Instance.Status.Migrations {
+			Expect(status.Completed).To(BeTrue())
+			Expect(status.CompletionTime).ToNot(BeNil())
+			Expect(status.FromVersion).To(Equal("v0.1.0"))
+		}
+	})
+
+	It("should run each migration only once", func() {
+		registry := newRegistry(migration("first", "", "0.2.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(Equal([]string{"first"}))
+	})
+
+	It("should not run migrations for versions, that were already deployed", func() {
+		request.Instance.Status.ObservedVersion = "v0.2.0"
+		registry := newRegistry(migration("first", "", "0.2.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(BeEmpty())
+		Expect(request.Instance.Status.Migrations).To(BeEmpty())
+	})
+
+	It("should not run migrations for newer versions of the operator", func() {
+		registry := newRegistry(migration("future", "", "0.4.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(BeEmpty())
+	})
+
+	It("should not run migrations from newer versions than deployed", func() {
+		registry := newRegistry(migration("first", "0.1.5", "0.2.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(BeEmpty())
+	})
+
+	It("should not run migrations on a fresh install", func() {
+		request.Instance.Status.ObservedVersion = ""
+		registry := newRegistry(migration("first", "0.1.5", "0.2.0"), migration("future", "", "0.4.0"))
+		Expect(registry.RecordPending(request)).To(BeFalse())
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(BeEmpty())
+		Expect(request.Instance.Status.Migrations).To(BeEmpty())
+	})
+
+	It("should run all migrations when the deployed version is unknown", func() {
+		request.Instance.Status.ObservedVersion = "dev-build"
+		os.Setenv(environment.OperatorVersionKey, "devel")
+		registry := newRegistry(migration("first", "0.1.5", "0.2.0"), migration("future", "", "0.4.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(Equal([]string{"first", "future"}))
+	})
+
+	It("should resume with the failed migration", func() {
+		failing := migration("failing", "", "0.2.0")
+		failing.Migrate = func(*common.Request) error {
+			return errors.New("test error")
+		}
+		registry := newRegistry(failing, migration("second", "", "0.3.0"))
+
+		Expect(registry.Run(request)).To(MatchError(ContainSubstring("test error")))
+		Expect(applied).To(BeEmpty(), "later migrations should wait for the failed one")
+		Expect(request.Instance.Status.Migrations).To(HaveLen(2))
+		Expect(request.Instance.Status.Migrations[0].Completed).To(BeFalse())
+		Expect(request.Instance.Status.Migrations[0].Error).To(Equal("test error"))
+		Expect(request.Instance.Status.Migrations[1].Completed).To(BeFalse())
+		Expect(request.Instance.Status.Migrations[1].Error).To(BeEmpty())
+
+		failing.Migrate = func(*common.Request) error {
+			applied = append(applied, "failing")
+			return nil
+		}
+		registry = newRegistry(failing, migration("second", "", "0.3.0"))
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(Equal([]string{"failing", "second"}))
+		Expect(request.Instance.Status.Migrations).To(HaveLen(2))
+		Expect(request.Instance.Status.Migrations[0].Completed).To(BeTrue())
+		Expect(request.Instance.Status.Migrations[0].Error).To(BeEmpty())
+	})
+
+	It("should keep status, when the migration replaces status of the CR", func() {
+		replacing := migration("replacing", "", "0.2.0")
+		replacing.Migrate = func(request *common.Request) error {
+			request.Instance.Status = *request.Instance.Status.DeepCopy()
+			return nil
+		}
+		Expect(newRegistry(replacing).Run(request)).To(Succeed())
+		Expect(request.Instance.Status.Migrations[0].Completed).To(BeTrue())
+	})
+
+	It("should record pending migrations before running them", func() {
+		registry := newRegistry(migration("first", "", "0.2.0"))
+		Expect(registry.RecordPending(request)).To(BeTrue())
+		Expect(applied).To(BeEmpty())
+		Expect(request.Instance.Status.Migrations).To(HaveLen(1))
+		Expect(request.Instance.Status.Migrations[0].Completed).To(BeFalse())
+
+		Expect(registry.Run(request)).To(Succeed())
+		Expect(applied).To(Equal([]string{"first"}))
+		Expect(registry.RecordPending(request)).To(BeFalse())
+	})
+
+	It("should forget incomplete migrations, that are not registered", func() {
+		request.Instance.Status.Migrations = []tekton.MigrationStatus{
+			{Name: "removed"},
+			{Name: "completed", Completed: true},
+		}
+		Expect(newRegistry().RecordPending(request)).To(BeFalse())
+		Expect(request.Instance.Status.Migrations).To(HaveLen(1))
+		Expect(request.Instance.Status.Migrations[0].Name).To(Equal("completed"))
+	})
+
+	It("should reject invalid migrations", func() {
+		_, err := NewRegistry(migration("first", "", "0.2.0"), migration("first", "", "0.3.0"))
+		Expect(err).To(MatchError(ContainSubstring("already registered")))
+		_, err = NewRegistry(migration("invalid", "", "latest"))
+		Expect(err).To(HaveOccurred())
+		_, err = NewRegistry(migration("invalid", "latest", "0.2.0"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should detect upgrade", func() {
+		Expect(IsUpgrading(request)).To(BeTrue())
+		request.Instance.Status.ObservedVersion = "v0.3.0"
+		Expect(IsUpgrading(request)).To(BeFalse())
+	})
+})
+
+func TestMigrations(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migrations Suite")
+}
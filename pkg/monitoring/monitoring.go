@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	OperandName      = "monitoring"
+	operandComponent = common.AppComponentMonitoring
+
+	// PrometheusRuleCRD is optional, the rule is deployed only when Prometheus operator is installed
+	PrometheusRuleCRD = "prometheusrules.monitoring.coreos.com"
+)
+
+// NewPrometheusRuleType returns an empty PrometheusRule, that can be used to watch or read rules.
+func NewPrometheusRuleType() *unstructured.Unstructured {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	return rule
+}
+
+// ReconcileRule creates the PrometheusRule in the namespace, or reverts changes of its labels and spec.
+// The rule does not depend on the TektonTasks CR, so alerts work also when the CR or Tekton is missing.
+func ReconcileRule(ctx context.Context, cli client.Client, namespace string) (controllerutil.OperationResult, error) {
+	expected := newPrometheusRule(namespace)
+
+	found := NewPrometheusRuleType()
+	found.SetName(expected.GetName())
+	found.SetNamespace(expected.GetNamespace())
+	return controllerutil.CreateOrUpdate(ctx, cli, found, func() error {
+		labels := found.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range expected.GetLabels() {
+			labels[key] = value
+		}
+		found.SetLabels(labels)
+		found.Object["spec"] = expected.Object["spec"]
+		return nil
+	})
+}
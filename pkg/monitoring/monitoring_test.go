@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+const namespace = "kubevirt"
+
+var _ = Describe("Monitoring", func() {
+	var (
+		ctx context.Context
+		cli client.Client
+		key client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cli = fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+		key = client.ObjectKey{Namespace: namespace, Name: PrometheusRuleName}
+	})
+
+	It("should create PrometheusRule", func() {
+		operation, err := ReconcileRule(ctx, cli, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(operation).To(Equal(controllerutil.OperationResultCreated))
+
+		rule := NewPrometheusRuleType()
+		Expect(cli.Get(ctx, key, rule)).To(Succeed())
+		Expect(rule.GetLabels()).To(HaveKeyWithValue("prometheus", "k8s"))
+		Expect(rule.GetLabels()).To(HaveKeyWithValue(common.AppKubernetesManagedByLabel, common.AppKubernetesManagedByValue))
+		Expect(rule.GetOwnerReferences()).To(BeEmpty())
+
+		groups, found, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(groups).To(HaveLen(1))
+		rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+		Expect(rules).To(HaveLen(len(alertRules)))
+	})
+
+	It("should not update unchanged PrometheusRule", func() {
+		_, err := ReconcileRule(ctx, cli, namespace)
+		Expect(err).ToNot(HaveOccurred())
+
+		operation, err := ReconcileRule(ctx, cli, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(operation).To(Equal(controllerutil.OperationResultNone))
+	})
+
+	It("should revert changes of PrometheusRule", func() {
+		_, err := ReconcileRule(ctx, cli, namespace)
+		Expect(err).ToNot(HaveOccurred())
+
+		rule := NewPrometheusRuleType()
+		Expect(cli.Get(ctx, key, rule)).To(Succeed())
+		rule.Object["spec"] = map[string]interface{}{}
+		rule.SetLabels(map[string]string{"custom": "label"})
+		Expect(cli.Update(ctx, rule)).To(Succeed())
+
+		operation, err := ReconcileRule(ctx, cli, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(operation).To(Equal(controllerutil.OperationResultUpdated))
+
+		Expect(cli.Get(ctx, key, rule)).To(Succeed())
+		Expect(rule.Object["spec"]).To(Equal(newPrometheusRule(namespace).Object["spec"]))
+		Expect(rule.GetLabels()).To(HaveKeyWithValue(common.AppKubernetesManagedByLabel, common.AppKubernetesManagedByValue))
+		Expect(rule.GetLabels()).To(HaveKeyWithValue("custom", "label"))
+	})
+
+	It("every alert should have a runbook", func() {
+		for _, rule := range alertRules {
+			annotations := rule.toUnstructured()["annotations"].(map[string]interface{})
+			Expect(annotations["runbook_url"]).To(Equal(runbookURLBase+rule.alert+".md"), rule.alert)
+			Expect(annotations["summary"]).ToNot(BeEmpty(), rule.alert)
+		}
+	})
+})
+
+func TestMonitoring(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Monitoring Suite")
+}
@@ -0,0 +1,128 @@
+package monitoring
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+const (
+	PrometheusRuleName = "tekton-tasks-operator-rules"
+
+	runbookURLBase = "https://github.com/kubevirt/tekton-tasks-operator/blob/main/docs/runbooks/"
+
+	// The duration, for which the operator has to fail before the alert fires
+	notReconcilingDuration = "15m"
+	// The number of reverts of one kind in an hour, that indicates someone is fighting the operator
+	revertedChangesThreshold = 5
+)
+
+var prometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+// Labels used by OpenShift cluster monitoring to select rules
+var prometheusRuleLabels = map[string]string{
+	"prometheus": "k8s",
+	"role":       "alert-rules",
+}
+
+type alertRule struct {
+	alert        string
+	expr         string
+	duration     string
+	severity     string
+	healthImpact string
+	summary      string
+	description  string
+}
+
+var alertRules = []alertRule{{
+	alert:        "TektonTasksOperatorNotReconcilingProperly",
+	expr:         "max(tekton_operator_reconciling_properly) == 0",
+	duration:     notReconcilingDuration,
+	severity:     "warning",
+	healthImpact: "warning",
+	summary:      "The tekton-tasks-operator is not reconciling properly",
+	description:  fmt.Sprintf("The tekton-tasks-operator has not reconciled all resources successfully for %s.", notReconcilingDuration),
+}, {
+	alert:        "TektonTasksCRDegraded",
+	expr:         "max(tekton_operator_cr_degraded) == 1",
+	duration:     "5m",
+	severity:     "warning",
+	healthImpact: "warning",
+	summary:      "The TektonTasks CR is degraded",
+	description:  "The Degraded condition of the TektonTasks CR is True. Check its conditions for the failing resources.",
+}, {
+	alert:        "TektonTasksResourcesRepeatedlyReverted",
+	expr:         fmt.Sprintf("sum by (kind) (increase(tekton_operator_resource_operations_total{operation=\"reverted\"}[1h])) >= %d", revertedChangesThreshold),
+	severity:     "warning",
+	healthImpact: "none",
+	summary:      "Changes of resources managed by the tekton-tasks-operator are repeatedly reverted",
+	description:  "Resources of kind {{ $labels.kind }} were reverted {{ $value }} times in the last hour. Another component or user is modifying them.",
+}, {
+	alert:        "TektonTasksRequiredCRDsMissing",
+	expr:         "max(tekton_operator_missing_crds) > 0",
+	duration:     "10m",
+	severity:     "warning",
+	healthImpact: "critical",
+	summary:      "CRDs required by the tekton-tasks-operator are missing",
+	description:  "{{ $value }} CRDs required by the tekton-tasks-operator are not installed. Tekton Pipelines may not be installed.",
+}}
+
+func newPrometheusRule(namespace string) *unstructured.Unstructured {
+	rules := make([]interface{}, 0, len(alertRules))
+	for _, rule := range alertRules {
+		rules = append(rules, rule.toUnstructured())
+	}
+
+	labels := make(map[string]string, len(prometheusRuleLabels)+3)
+	for key, value := range prometheusRuleLabels {
+		labels[key] = value
+	}
+	labels[common.AppKubernetesNameLabel] = OperandName
+	labels[common.AppKubernetesComponentLabel] = operandComponent.String()
+	labels[common.AppKubernetesManagedByLabel] = common.AppKubernetesManagedByValue
+
+	prometheusRule := &unstructured.Unstructured{}
+	prometheusRule.SetGroupVersionKind(prometheusRuleGVK)
+	prometheusRule.SetName(PrometheusRuleName)
+	prometheusRule.SetNamespace(namespace)
+	prometheusRule.SetLabels(labels)
+	prometheusRule.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  "tekton_tasks_operator.rules",
+				"rules": rules,
+			},
+		},
+	}
+	return prometheusRule
+}
+
+func (a alertRule) toUnstructured() map[string]interface{} {
+	rule := map[string]interface{}{
+		"alert": a.alert,
+		"expr":  a.expr,
+		"labels": map[string]interface{}{
+			"severity":                      a.severity,
+			"operator_health_impact":        a.healthImpact,
+			"kubernetes_operator_part_of":   "kubevirt",
+			"kubernetes_operator_component": "tekton-tasks-operator",
+		},
+		"annotations": map[string]interface{}{
+			"summary":     a.summary,
+			"description": a.description,
+			"runbook_url": runbookURLBase + a.alert + ".md",
+		},
+	}
+	if a.duration != "" {
+		rule["for"] = a.duration
+	}
+	return rule
+}
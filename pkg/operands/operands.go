@@ -1,6 +1,7 @@
 package operands
 
 import (
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
@@ -16,6 +17,11 @@ type Operand interface {
 	// RequiredCrds returns names of CRDs, that need to be installed for the operand to work.
 	RequiredCrds() []string
 
+	// OptionalAPIGroups returns API groups, that some resources of the operand depend on.
+	// Resources are deployed only when their API groups are available, and the operand
+	// is reconciled again, when CRDs of these groups are installed or removed.
+	OptionalAPIGroups() []string
+
 	// Reconcile creates and updates resources.
 	Reconcile(*common.Request) ([]common.ReconcileResult, error)
 
@@ -25,4 +31,33 @@ type Operand interface {
 
 	// Name returns the name of the operand
 	Name() string
+
+	// Dependencies returns names of operands, that need to be reconciled before this operand.
+	Dependencies() []string
+
+	// ReadyConditionType returns the type of the TektonTasks condition, that reports readiness of the operand.
+	ReadyConditionType() conditionsv1.ConditionType
+}
+
+// SortByDependencies orders operands, so that each operand comes after all operands it depends on.
+func SortByDependencies(operands []Operand) ([]Operand, error) {
+	names := make([]string, 0, len(operands))
+	dependencies := make(map[string][]string, len(operands))
+	byName := make(map[string]Operand, len(operands))
+	for _, operand := range operands {
+		names = append(names, operand.Name())
+		dependencies[operand.Name()] = operand.Dependencies()
+		byName[operand.Name()] = operand
+	}
+
+	sortedNames, err := common.SortByDependencies(names, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]Operand, 0, len(sortedNames))
+	for _, name := range sortedNames {
+		sorted = append(sorted, byName[name])
+	}
+	return sorted, nil
 }
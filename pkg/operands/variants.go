@@ -0,0 +1,109 @@
+package operands
+
+import (
+	"sort"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// variants is an operand, that delegates reconciliation to one of its variants, selected for each request.
+// Resources of the previously selected variant are pruned by the newly selected one.
+type variants struct {
+	variants map[string]Operand
+	// names are sorted names of the variants, so the delegation is deterministic
+	names  []string
+	choose func(*common.Request) string
+}
+
+var _ Operand = &variants{}
+
+// NewVariants returns an operand, that reconciles the variant returned by choose.
+// All variants must have the same name, dependencies and ready condition type.
+func NewVariants(operands map[string]Operand, choose func(*common.Request) string) Operand {
+	names := make([]string, 0, len(operands))
+	for name := range operands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &variants{
+		variants: operands,
+		names:    names,
+		choose:   choose,
+	}
+}
+
+func (v *variants) WatchTypes() []client.Object {
+	var types []client.Object
+	for _, name := range v.names {
+		types = append(types, v.variants[name].WatchTypes()...)
+	}
+	return types
+}
+
+func (v *variants) WatchClusterTypes() []client.Object {
+	var types []client.Object
+	for _, name := range v.names {
+		types = append(types, v.variants[name].WatchClusterTypes()...)
+	}
+	return types
+}
+
+func (v *variants) RequiredCrds() []string {
+	var crds []string
+	for _, name := range v.names {
+		crds = append(crds, v.variants[name].RequiredCrds()...)
+	}
+	return crds
+}
+
+func (v *variants) OptionalAPIGroups() []string {
+	var groups []string
+	for _, name := range v.names {
+		groups = append(groups, v.variants[name].OptionalAPIGroups()...)
+	}
+	return groups
+}
+
+func (v *variants) Reconcile(request *common.Request) ([]common.ReconcileResult, error) {
+	return v.selected(request).Reconcile(request)
+}
+
+// Cleanup removes resources of all variants, because the selected variant could have changed.
+func (v *variants) Cleanup(request *common.Request) ([]common.CleanupResult, error) {
+	var results []common.CleanupResult
+	for _, name := range v.names {
+		variantResults, err := v.variants[name].Cleanup(request)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, variantResults...)
+	}
+	return results, nil
+}
+
+func (v *variants) Name() string {
+	return v.first().Name()
+}
+
+func (v *variants) Dependencies() []string {
+	return v.first().Dependencies()
+}
+
+func (v *variants) ReadyConditionType() conditionsv1.ConditionType {
+	return v.first().ReadyConditionType()
+}
+
+func (v *variants) selected(request *common.Request) Operand {
+	if operand, ok := v.variants[v.choose(request)]; ok {
+		return operand
+	}
+	return v.first()
+}
+
+func (v *variants) first() Operand {
+	return v.variants[v.names[0]]
+}
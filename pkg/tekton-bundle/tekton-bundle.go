@@ -2,22 +2,17 @@ package tekton_bundle
 
 import (
 	"bytes"
-	"context"
 	"io"
 	"io/ioutil"
 	"path/filepath"
 
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
-	openshiftconfigv1 "github.com/openshift/api/config/v1"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	yamlv2 "gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -45,77 +40,75 @@ type Bundle struct {
 	ConfigMaps      []v1.ConfigMap
 }
 
-func ReadTasksBundle(cl client.Reader, ctx context.Context) (*Bundle, error) {
-	isOpenshift, err := runningOnOpenshift(cl, ctx)
-	if err != nil {
-		return nil, err
-	}
+// Variant is a variant of the bundles, for a set of platform capabilities.
+type Variant string
 
-	path := getTasksBundlePath(isOpenshift)
-	files, err := readFile(path)
-	if err != nil {
-		return nil, err
-	}
+const (
+	VariantOKD        Variant = "okd"
+	VariantKubernetes Variant = "kubernetes"
+)
 
-	tektonObjs, err := decodeObjectsFromFiles(files)
-	if err != nil {
-		return nil, err
-	}
+var Variants = []Variant{VariantOKD, VariantKubernetes}
 
-	return tektonObjs, nil
+// SelectVariant returns the variant of the bundles, that can be deployed on a cluster with the capabilities.
+// The OKD variant contains tasks and pipelines, that use OpenShift templates.
+func SelectVariant(capabilities common.Capabilities) Variant {
+	if capabilities.Has(common.CapabilityTemplates) {
+		return VariantOKD
+	}
+	return VariantKubernetes
 }
 
-func ReadPipelineBundle(cl client.Reader, ctx context.Context) (*Bundle, error) {
-	isOpenshift, err := runningOnOpenshift(cl, ctx)
-	if err != nil {
-		return nil, err
-	}
+// ReadTasksBundles reads all variants of the tasks bundle, the variant is selected during reconciliation.
+func ReadTasksBundles() (map[Variant]*Bundle, error) {
+	bundles := make(map[Variant]*Bundle, len(Variants))
+	for _, variant := range Variants {
+		files, err := readFile(getTasksBundlePath(variant))
+		if err != nil {
+			return nil, err
+		}
 
-	path := getPipelineBundlePath(isOpenshift)
-	files, err := readFolder(path)
-	if err != nil {
-		return nil, err
+		tektonObjs, err := decodeObjectsFromFiles(files)
+		if err != nil {
+			return nil, err
+		}
+		bundles[variant] = tektonObjs
 	}
+	return bundles, nil
+}
 
-	tektonObjs, err := decodeObjectsFromFiles(files)
-	if err != nil {
-		return nil, err
-	}
+// ReadPipelineBundles reads all variants of the pipelines bundle, the variant is selected during reconciliation.
+func ReadPipelineBundles() (map[Variant]*Bundle, error) {
+	bundles := make(map[Variant]*Bundle, len(Variants))
+	for _, variant := range Variants {
+		files, err := readFolder(getPipelineBundlePath(variant))
+		if err != nil {
+			return nil, err
+		}
 
-	return tektonObjs, nil
+		tektonObjs, err := decodeObjectsFromFiles(files)
+		if err != nil {
+			return nil, err
+		}
+		bundles[variant] = tektonObjs
+	}
+	return bundles, nil
 }
 
-func getPipelineBundlePath(isOpenshift bool) string {
-	if isOpenshift {
+func getPipelineBundlePath(variant Variant) string {
+	if variant == VariantOKD {
 		return tektonPipelinesOKDBundleDir
 	}
 	return tektonPipelinesKubernetesBundleDir
 }
 
-func getTasksBundlePath(isOpenshift bool) string {
-	if isOpenshift {
+func getTasksBundlePath(variant Variant) string {
+	if variant == VariantOKD {
 		return filepath.Join(tektonTasksOKDBundleDir, "kubevirt-tekton-tasks-okd-"+operands.TektonTasksVersion+".yaml")
 	}
 	return filepath.Join(tektonTasksKubernetesBundleDir, "kubevirt-tekton-tasks-kubernetes-"+operands.TektonTasksVersion+".yaml")
 }
 
-func runningOnOpenshift(cl client.Reader, ctx context.Context) (bool, error) {
-	clusterVersion := &openshiftconfigv1.ClusterVersion{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "version",
-		},
-	}
-	if err := cl.Get(ctx, client.ObjectKeyFromObject(clusterVersion), clusterVersion); err != nil {
-		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
-			// Not on OpenShift
-			return false, nil
-		} else {
-			return false, err
-		}
-	}
-	return true, nil
-}
-
 func readFile(fileName string) ([][]byte, error) {
 	file, err := ioutil.ReadFile(fileName)
 	if err != nil {
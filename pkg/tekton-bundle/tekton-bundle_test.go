@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -22,25 +23,36 @@ const (
 var _ = Describe("Tekton bundle", func() {
 
 	It("should return correct pipeline folder path on okd", func() {
-		path := getPipelineBundlePath(true)
+		path := getPipelineBundlePath(VariantOKD)
 		Expect(path).To(Equal("/data/tekton-pipelines/okd/"))
 	})
 
 	It("should return correct pipeline folder path on kubernetes", func() {
-		path := getPipelineBundlePath(false)
+		path := getPipelineBundlePath(VariantKubernetes)
 		Expect(path).To(Equal("/data/tekton-pipelines/kubernetes/"))
 	})
 
 	It("should return correct task path on okd", func() {
-		path := getTasksBundlePath(true)
+		path := getTasksBundlePath(VariantOKD)
 		Expect(path).To(Equal("/data/tekton-tasks/okd/kubevirt-tekton-tasks-okd-" + operands.TektonTasksVersion + ".yaml"))
 	})
 
 	It("should return correct task path on kubernetes", func() {
-		path := getTasksBundlePath(false)
+		path := getTasksBundlePath(VariantKubernetes)
 		Expect(path).To(Equal("/data/tekton-tasks/kubernetes/kubevirt-tekton-tasks-kubernetes-" + operands.TektonTasksVersion + ".yaml"))
 	})
 
+	It("should select okd variant when templates are available", func() {
+		capabilities := common.Capabilities{common.CapabilityTemplates: true}
+		Expect(SelectVariant(capabilities)).To(Equal(VariantOKD))
+	})
+
+	It("should select kubernetes variant when templates are not available", func() {
+		capabilities := common.Capabilities{common.CapabilityRoutes: true}
+		Expect(SelectVariant(capabilities)).To(Equal(VariantKubernetes))
+		Expect(SelectVariant(nil)).To(Equal(VariantKubernetes))
+	})
+
 	It("should load correct files and convert them", func() {
 		path, _ := os.Getwd()
 
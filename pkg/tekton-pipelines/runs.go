@@ -0,0 +1,30 @@
+package tekton_pipelines
+
+import (
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// runsUsingPipeline returns running PipelineRuns of the Pipeline.
+func runsUsingPipeline(request *common.Request, found client.Object) ([]tekton.ResourceReference, error) {
+	pipelineRuns := &pipeline.PipelineRunList{}
+	err := request.Client.List(request.Context, pipelineRuns,
+		client.InNamespace(found.GetNamespace()),
+		client.MatchingLabels{tektonpipeline.PipelineLabelKey: found.GetName()})
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []tekton.ResourceReference
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		if !pipelineRun.IsDone() {
+			runs = append(runs, tekton.ResourceReference{Kind: pipelineRun.GetGroupVersionKind().Kind, Namespace: pipelineRun.Namespace, Name: pipelineRun.Name})
+		}
+	}
+	return runs, nil
+}
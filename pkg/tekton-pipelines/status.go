@@ -0,0 +1,58 @@
+package tekton_pipelines
+
+import (
+	"fmt"
+	"strings"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// Pipeline params with this suffix hold a name of a ConfigMap in the pipeline namespace
+const configMapParamSuffix = "configmapname"
+
+// pipelineStatus returns a StatusFunc, that checks if ClusterTasks referenced by a Pipeline
+// and ConfigMaps referenced by default values of its params exist. Errors other than NotFound,
+// for example Forbidden, are returned, because they are failures of the operator, not of the Pipeline.
+func pipelineStatus(request *common.Request) common.ResourceStatusFunc {
+	return func(resource client.Object) (common.ResourceStatus, error) {
+		p := resource.(*pipeline.Pipeline)
+
+		var missing []string
+		for _, task := range append(append([]pipeline.PipelineTask{}, p.Spec.Tasks...), p.Spec.Finally...) {
+			if task.TaskRef == nil || task.TaskRef.Kind != pipeline.ClusterTaskKind {
+				continue
+			}
+			exists, err := common.ResourceExists(request, client.ObjectKey{Name: task.TaskRef.Name}, &pipeline.ClusterTask{})
+			if err != nil {
+				return common.ResourceStatus{}, fmt.Errorf("failed to get ClusterTask %s: %w", task.TaskRef.Name, err)
+			}
+			if !exists {
+				missing = append(missing, "ClusterTask "+task.TaskRef.Name)
+			}
+		}
+
+		for _, param := range p.Spec.Params {
+			if !strings.HasSuffix(strings.ToLower(param.Name), configMapParamSuffix) ||
+				param.Default == nil || param.Default.StringVal == "" {
+				continue
+			}
+			key := client.ObjectKey{Namespace: p.Namespace, Name: param.Default.StringVal}
+			exists, err := common.ResourceExists(request, key, &v1.ConfigMap{})
+			if err != nil {
+				return common.ResourceStatus{}, fmt.Errorf("failed to get ConfigMap %s: %w", key, err)
+			}
+			if !exists {
+				missing = append(missing, "ConfigMap "+key.String())
+			}
+		}
+
+		if len(missing) > 0 {
+			return common.DegradedStatus(fmt.Sprintf("Referenced resources do not exist: %s", strings.Join(missing, ", "))), nil
+		}
+		return common.ResourceStatus{}, nil
+	}
+}
@@ -0,0 +1,91 @@
+package tekton_pipelines
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+var _ = Describe("Pipeline status", func() {
+	var mockedRequest *common.Request
+	var testPipeline *pipeline.Pipeline
+
+	BeforeEach(func() {
+		mockedRequest = getMockedRequest()
+		testPipeline = &pipeline.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pipeline",
+				Namespace: namespace,
+			},
+			Spec: pipeline.PipelineSpec{
+				Params: []pipeline.ParamSpec{{
+					Name:    "customizeConfigMapName",
+					Default: pipeline.NewArrayOrString("test-cm"),
+				}, {
+					Name:    "otherParam",
+					Default: pipeline.NewArrayOrString("not-a-config-map"),
+				}},
+				Tasks: []pipeline.PipelineTask{{
+					Name:    "copy",
+					TaskRef: &pipeline.TaskRef{Name: "copy-template", Kind: pipeline.ClusterTaskKind},
+				}},
+				Finally: []pipeline.PipelineTask{{
+					Name:    "cleanup",
+					TaskRef: &pipeline.TaskRef{Name: "cleanup-vm", Kind: pipeline.ClusterTaskKind},
+				}},
+			},
+		}
+	})
+
+	It("should be degraded when referenced resources do not exist", func() {
+		status, err := pipelineStatus(mockedRequest)(testPipeline)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Degraded).ToNot(BeNil())
+		Expect(*status.Degraded).To(ContainSubstring("ClusterTask copy-template"))
+		Expect(*status.Degraded).To(ContainSubstring("ClusterTask cleanup-vm"))
+		Expect(*status.Degraded).To(ContainSubstring("ConfigMap " + namespace + "/test-cm"))
+		Expect(*status.Degraded).ToNot(ContainSubstring("not-a-config-map"))
+	})
+
+	It("should be healthy when referenced resources exist", func() {
+		for _, name := range []string{"copy-template", "cleanup-vm"} {
+			task := &pipeline.ClusterTask{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			Expect(mockedRequest.Client.Create(mockedRequest.Context, task)).To(Succeed())
+		}
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: namespace}}
+		Expect(mockedRequest.Client.Create(mockedRequest.Context, cm)).To(Succeed())
+
+		status, err := pipelineStatus(mockedRequest)(testPipeline)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(common.ResourceStatus{}))
+	})
+
+	It("should return error instead of degraded status, when reading referenced resources is forbidden", func() {
+		mockedRequest.UncachedReader = forbiddenReader{}
+
+		status, err := pipelineStatus(mockedRequest)(testPipeline)
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "expected forbidden error, got: %v", err)
+		Expect(status).To(Equal(common.ResourceStatus{}))
+	})
+})
+
+// forbiddenReader simulates an uncached reader, that is not allowed to read resources by RBAC.
+type forbiddenReader struct{}
+
+func (forbiddenReader) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return errors.NewForbidden(schema.GroupResource{}, key.Name, fmt.Errorf("access denied"))
+}
+
+func (forbiddenReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return errors.NewForbidden(schema.GroupResource{}, "", fmt.Errorf("access denied"))
+}
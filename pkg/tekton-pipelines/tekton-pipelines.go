@@ -1,13 +1,15 @@
 package tekton_pipelines
 
 import (
-	"fmt"
 	"regexp"
 
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
-	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/migrations"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
 	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
+	tektontasks "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-tasks"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
@@ -16,19 +18,45 @@ import (
 )
 
 // +kubebuilder:rbac:groups=tekton.dev,resources=pipelines,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=*,resources=configmaps,verbs=list;watch;create;delete
+// +kubebuilder:rbac:groups=*,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
 
 const (
 	namespacePattern = "^(openshift|kube)-"
 	operandName      = "tekton-pipelines"
 	operandComponent = common.AppComponentTektonPipelines
+
+	clusterRolesGroup    = "clusterRoles"
+	serviceAccountsGroup = "serviceAccounts"
+	roleBindingsGroup    = "roleBindings"
+	configMapsGroup      = "configMaps"
+	pipelinesGroup       = "pipelines"
 )
 
 var namespaceRegex = regexp.MustCompile(namespacePattern)
 
 var requiredCRDs = []string{"tasks.tekton.dev"}
 
+// pipelineAPIGroups lists API groups of resources, that pipelines create themselves.
+// Pipelines also depend on API groups of ClusterTasks they reference.
+var pipelineAPIGroups = map[string][]string{
+	"windows10-installer": {common.KubeVirtAPIGroup, common.CDIAPIGroup},
+	"windows10-customize": {common.KubeVirtAPIGroup, common.CDIAPIGroup},
+}
+
+// PipelineAPIGroups returns API groups, that the pipeline depends on.
+func PipelineAPIGroups(p *pipeline.Pipeline) []string {
+	groups := append([]string{}, pipelineAPIGroups[p.Name]...)
+	for _, tasks := range [][]pipeline.PipelineTask{p.Spec.Tasks, p.Spec.Finally} {
+		for _, task := range tasks {
+			if task.TaskRef != nil && task.TaskRef.Kind == pipeline.ClusterTaskKind {
+				groups = append(groups, tektontasks.TaskAPIGroups(task.TaskRef.Name)...)
+			}
+		}
+	}
+	return groups
+}
+
 func init() {
 	utilruntime.Must(pipeline.AddToScheme(common.Scheme))
 }
@@ -58,6 +86,15 @@ func (t *tektonPipelines) Name() string {
 	return operandName
 }
 
+// Dependencies returns the tekton-tasks operand, because pipelines reference its ClusterTasks.
+func (t *tektonPipelines) Dependencies() []string {
+	return []string{tektontasks.OperandName}
+}
+
+func (t *tektonPipelines) ReadyConditionType() conditionsv1.ConditionType {
+	return tekton.ConditionTektonPipelinesReady
+}
+
 func (t *tektonPipelines) WatchClusterTypes() []client.Object {
 	return []client.Object{
 		&pipeline.Pipeline{},
@@ -76,74 +113,154 @@ func (t *tektonPipelines) RequiredCrds() []string {
 	return requiredCRDs
 }
 
+func (t *tektonPipelines) OptionalAPIGroups() []string {
+	var groups []string
+	for i := range t.pipelines {
+		groups = append(groups, PipelineAPIGroups(&t.pipelines[i])...)
+	}
+	return groups
+}
+
 func (t *tektonPipelines) Reconcile(request *common.Request) ([]common.ReconcileResult, error) {
 	var results []common.ReconcileResult
-	var reconcileFunc []common.ReconcileFunc
-	reconcileFunc = append(reconcileFunc, reconcileClusterRolesFuncs(request, t.clusterRoles)...)
-	reconcileFunc = append(reconcileFunc, reconcileTektonPipelinesFuncs(t.pipelines)...)
-	reconcileFunc = append(reconcileFunc, reconcileConfigMapsFuncs(t.configMaps)...)
-	reconcileFunc = append(reconcileFunc, reconcileRoleBindingsFuncs(request, t.roleBindings)...)
-	reconcileFunc = append(reconcileFunc, reconcileServiceAccountsFuncs(request, t.serviceAccounts)...)
-
-	reconcileTektonBundleResults, err := common.CollectResourceStatus(request, reconcileFunc...)
+	var deployedPipelines []*pipeline.Pipeline
+	var skippedPipelines []client.Object
+	for i := range t.pipelines {
+		p := &t.pipelines[i]
+		if missing := request.APIGroups.Missing(PipelineAPIGroups(p)); len(missing) > 0 {
+			skipped := p.DeepCopy()
+			skipped.Namespace = request.Instance.Spec.Pipelines.Namespace
+			skippedPipelines = append(skippedPipelines, skipped)
+			results = append(results, common.MissingAPIGroupsResult(skipped, missing))
+			continue
+		}
+		deployedPipelines = append(deployedPipelines, p)
+	}
+
+	// Pipelines with missing API groups are left untouched, so pipelines deployed before the API groups were removed are kept
+	groups := t.resourceGroups(deployedPipelines, t.deployedServiceAccounts(request))
+	reconcileTektonBundleResults, err := common.ReconcileGroups(request, groups...)
 	if err != nil {
 		return nil, err
 	}
 
-	upgradingNow := isUpgradingNow(request)
+	upgradingNow := migrations.IsUpgrading(request)
 	for _, r := range reconcileTektonBundleResults {
-		if !upgradingNow && (r.OperationResult == common.OperationResultUpdated) {
-			request.Logger.Info(fmt.Sprintf("Changes reverted in tekton pipeline: %s", r.Resource.GetName()))
+		if !upgradingNow && (r.OperationResult == common.OperationResultUpdated) && !r.DesiredStateChanged() {
+			common.ReportRevertedChanges(request, r)
 		}
 	}
-	return append(results, reconcileTektonBundleResults...), nil
+	results = append(results, reconcileTektonBundleResults...)
+
+	if upgradingNow || request.BundleVariantChanged {
+		// Resources removed from the bundle by the upgrade, or missing in the selected variant, are deleted
+		// Skipped pipelines are kept
+		desired := append(common.GroupResources(groups), skippedPipelines...)
+		pruneResults, err := common.PruneResources(request, operandComponent, t.WatchClusterTypes(), desired)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, pruneResults...)
+	}
+	return results, nil
 }
 
 func (t *tektonPipelines) Cleanup(request *common.Request) ([]common.CleanupResult, error) {
-	var objects []client.Object
-	for _, p := range t.pipelines {
-		o := p.DeepCopy()
-		objects = append(objects, o)
+	return common.CleanupGroups(request, t.resourceGroups(t.allPipelines(), t.allServiceAccounts())...)
+}
+
+func (t *tektonPipelines) resourceGroups(deployedPipelines []*pipeline.Pipeline, serviceAccounts []*v1.ServiceAccount) []common.ResourceGroup {
+	var pipelines, configMaps, roleBindings, sas, clusterRoles []client.Object
+	for _, p := range deployedPipelines {
+		pipelines = append(pipelines, p)
 	}
-	for _, cm := range t.configMaps {
-		o := cm.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.configMaps {
+		configMaps = append(configMaps, &t.configMaps[i])
 	}
-	for _, rb := range t.roleBindings {
-		o := rb.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.roleBindings {
+		roleBindings = append(roleBindings, &t.roleBindings[i])
 	}
-	for _, sa := range t.serviceAccounts {
-		o := sa.DeepCopy()
-		objects = append(objects, o)
+	for _, sa := range serviceAccounts {
+		sas = append(sas, sa)
 	}
-	for _, cr := range t.clusterRoles {
-		o := cr.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.clusterRoles {
+		clusterRoles = append(clusterRoles, &t.clusterRoles[i])
 	}
 
-	return common.DeleteAll(request, objects...)
+	return []common.ResourceGroup{{
+		Name:      clusterRolesGroup,
+		Resources: clusterRoles,
+		Funcs:     reconcileClusterRolesFuncs(t.clusterRoles),
+	}, {
+		Name:      serviceAccountsGroup,
+		Resources: sas,
+		Funcs:     reconcileServiceAccountsFuncs(serviceAccounts),
+	}, {
+		Name:      roleBindingsGroup,
+		DependsOn: []string{clusterRolesGroup, serviceAccountsGroup},
+		Resources: roleBindings,
+		Funcs:     reconcileRoleBindingsFuncs(t.roleBindings),
+	}, {
+		Name:      configMapsGroup,
+		Resources: configMaps,
+		Funcs:     reconcileConfigMapsFuncs(t.configMaps),
+	}, {
+		Name:      pipelinesGroup,
+		DependsOn: []string{configMapsGroup},
+		Resources: pipelines,
+		Funcs:     reconcileTektonPipelinesFuncs(deployedPipelines),
+	}}
 }
 
-func isUpgradingNow(request *common.Request) bool {
-	return request.Instance.Status.ObservedVersion != environment.GetOperatorVersion()
+func (t *tektonPipelines) allPipelines() []*pipeline.Pipeline {
+	pipelines := make([]*pipeline.Pipeline, 0, len(t.pipelines))
+	for i := range t.pipelines {
+		pipelines = append(pipelines, &t.pipelines[i])
+	}
+	return pipelines
 }
 
-func reconcileTektonPipelinesFuncs(pipelines []pipeline.Pipeline) []common.ReconcileFunc {
+func (t *tektonPipelines) allServiceAccounts() []*v1.ServiceAccount {
+	sas := make([]*v1.ServiceAccount, 0, len(t.serviceAccounts))
+	for i := range t.serviceAccounts {
+		sas = append(sas, &t.serviceAccounts[i])
+	}
+	return sas
+}
+
+func (t *tektonPipelines) deployedServiceAccounts(request *common.Request) []*v1.ServiceAccount {
+	sas := make([]*v1.ServiceAccount, 0, len(t.serviceAccounts))
+	for i := range t.serviceAccounts {
+		sa := &t.serviceAccounts[i]
+		// deploy pipeline SA only in `^(openshift|kube)-` namespaces
+		if !namespaceRegex.MatchString(request.Instance.Spec.Pipelines.Namespace) && sa.Name == "pipeline" {
+			continue
+		}
+		sas = append(sas, sa)
+	}
+	return sas
+}
+
+func reconcileTektonPipelinesFuncs(pipelines []*pipeline.Pipeline) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(pipelines))
 	for i := range pipelines {
-		p := &pipelines[i]
+		p := pipelines[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			namespace := request.Instance.Spec.Pipelines.Namespace
 			p.Namespace = namespace
 			return common.CreateOrUpdate(request).
-				ClusterResource(p).
+				ClusterResource(adaptToTopology(request, p)).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newPipeline := newRes.(*pipeline.Pipeline)
 					foundPipeline := foundRes.(*pipeline.Pipeline)
 					foundPipeline.Spec = newPipeline.Spec
 				}).
+				DeferSpecUpdate(func(resource client.Object) interface{} {
+					return resource.(*pipeline.Pipeline).Spec
+				}, runsUsingPipeline).
+				StatusFunc(pipelineStatus(request)).
 				Reconcile()
 		})
 	}
@@ -160,6 +277,7 @@ func reconcileConfigMapsFuncs(configMaps []v1.ConfigMap) []common.ReconcileFunc
 			return common.CreateOrUpdate(request).
 				ClusterResource(cm).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newCM := newRes.(*v1.ConfigMap)
 					foundCM := foundRes.(*v1.ConfigMap)
@@ -171,15 +289,10 @@ func reconcileConfigMapsFuncs(configMaps []v1.ConfigMap) []common.ReconcileFunc
 	return funcs
 }
 
-func reconcileServiceAccountsFuncs(r *common.Request, sas []v1.ServiceAccount) []common.ReconcileFunc {
+func reconcileServiceAccountsFuncs(sas []*v1.ServiceAccount) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(sas))
 	for i := range sas {
-		sa := &sas[i]
-		// deploy pipeline SA only in `^(openshift|kube)-` namespaces
-		if !namespaceRegex.MatchString(r.Instance.Spec.Pipelines.Namespace) && sa.Name == "pipeline" {
-			continue
-		}
-
+		sa := sas[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			namespace := request.Instance.Namespace
 			if sa.Namespace == "" {
@@ -188,19 +301,21 @@ func reconcileServiceAccountsFuncs(r *common.Request, sas []v1.ServiceAccount) [
 			return common.CreateOrUpdate(request).
 				ClusterResource(sa).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newSA := newRes.(*v1.ServiceAccount)
 					foundSA := foundRes.(*v1.ServiceAccount)
 					foundSA.Labels = newSA.Labels
 					foundSA.Annotations = newSA.Annotations
 				}).
+				StatusFunc(common.ServiceAccountStatus()).
 				Reconcile()
 		})
 	}
 	return funcs
 }
 
-func reconcileClusterRolesFuncs(r *common.Request, crs []rbac.ClusterRole) []common.ReconcileFunc {
+func reconcileClusterRolesFuncs(crs []rbac.ClusterRole) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(crs))
 	for i := range crs {
 		cr := &crs[i]
@@ -208,6 +323,7 @@ func reconcileClusterRolesFuncs(r *common.Request, crs []rbac.ClusterRole) []com
 			return common.CreateOrUpdate(request).
 				ClusterResource(cr).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newCR := newRes.(*rbac.ClusterRole)
 					foundCR := foundRes.(*rbac.ClusterRole)
@@ -221,7 +337,7 @@ func reconcileClusterRolesFuncs(r *common.Request, crs []rbac.ClusterRole) []com
 	return funcs
 }
 
-func reconcileRoleBindingsFuncs(r *common.Request, rbs []rbac.RoleBinding) []common.ReconcileFunc {
+func reconcileRoleBindingsFuncs(rbs []rbac.RoleBinding) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(rbs))
 	for i := range rbs {
 		rb := &rbs[i]
@@ -237,12 +353,14 @@ func reconcileRoleBindingsFuncs(r *common.Request, rbs []rbac.RoleBinding) []com
 			return common.CreateOrUpdate(request).
 				ClusterResource(rb).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newTask := newRes.(*rbac.RoleBinding)
 					foundTask := foundRes.(*rbac.RoleBinding)
 					foundTask.RoleRef = newTask.RoleRef
 					foundTask.Subjects = newTask.Subjects
 				}).
+				StatusFunc(common.RoleBindingStatus(request)).
 				Reconcile()
 		})
 	}
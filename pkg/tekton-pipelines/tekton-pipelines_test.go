@@ -7,14 +7,19 @@ import (
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
+	tektontasks "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-tasks"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	osconfv1 "github.com/openshift/api/config/v1"
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -51,6 +56,182 @@ var _ = Describe("environments", func() {
 		Expect(len(functions)).To(Equal(6), "should return correct number of reconcile functions")
 	})
 
+	It("Reconcile function should skip pipelines with missing API groups", func() {
+		tp.pipelines[0].Spec.Tasks = []pipeline.PipelineTask{{
+			Name:    "copy-template",
+			TaskRef: &pipeline.TaskRef{Kind: pipeline.ClusterTaskKind, Name: "copy-template"},
+		}}
+		mockedRequest.APIGroups = common.APIGroups{}
+		mockedRequest.Instance.Spec.Pipelines.Namespace = namespace
+
+		results, err := tp.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+		Expect(results).To(HaveLen(6))
+
+		var skipped []common.ReconcileResult
+		for _, result := range results {
+			if result.OperationResult == common.OperationResultMissingAPIGroups {
+				skipped = append(skipped, result)
+			}
+		}
+		Expect(skipped).To(HaveLen(1))
+		Expect(skipped[0].Resource.GetName()).To(Equal("test-pipeline"))
+		Expect(skipped[0].Resource.GetNamespace()).To(Equal(namespace))
+		Expect(skipped[0].MissingAPIGroups).To(ConsistOf(common.TemplateAPIGroup))
+	})
+
+	It("Reconcile function should defer updates of pipelines used by running runs", func() {
+		mockedRequest.Instance.Spec.Pipelines.Namespace = namespace
+		_, err := tp.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		pipelineRun := &pipeline.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pipelinerun",
+				Namespace: namespace,
+				Labels:    map[string]string{tektonpipeline.PipelineLabelKey: "test-pipeline"},
+			},
+		}
+		Expect(mockedRequest.Client.Create(mockedRequest.Context, pipelineRun)).To(Succeed())
+
+		mockedRequest.Instance.Spec.UpdateStrategy.WaitForRuns = true
+		for i := range tp.pipelines {
+			tp.pipelines[i].Spec.Description = "updated"
+		}
+		results, err := tp.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		operationResults := map[string]common.OperationResult{}
+		for _, result := range results {
+			if _, isPipeline := result.Resource.(*pipeline.Pipeline); isPipeline {
+				operationResults[result.Resource.GetName()] = result.OperationResult
+			}
+		}
+		Expect(operationResults).To(Equal(map[string]common.OperationResult{
+			"test-pipeline":  common.OperationResultDeferred,
+			"test-pipeline2": common.OperationResultUpdated,
+		}))
+	})
+
+	It("PipelineAPIGroups function should return API groups of the pipeline and its tasks", func() {
+		p := &pipeline.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "windows10-installer"},
+			Spec: pipeline.PipelineSpec{
+				Tasks: []pipeline.PipelineTask{{
+					Name:    "copy-template",
+					TaskRef: &pipeline.TaskRef{Kind: pipeline.ClusterTaskKind, Name: "copy-template"},
+				}},
+				Finally: []pipeline.PipelineTask{{
+					Name:    "modify-data-object",
+					TaskRef: &pipeline.TaskRef{Kind: pipeline.ClusterTaskKind, Name: "modify-data-object"},
+				}},
+			},
+		}
+		Expect(PipelineAPIGroups(p)).To(ConsistOf(common.KubeVirtAPIGroup, common.CDIAPIGroup, common.TemplateAPIGroup, common.CDIAPIGroup))
+	})
+
+	Context("topology", func() {
+		const (
+			largeDataVolume = `{"apiVersion": "cdi.kubevirt.io/v1beta1", "kind": "DataVolume",
+				"spec": {"storage": {"resources": {"requests": {"storage": "60Gi"}}}}}`
+			smallDataVolume = `{"apiVersion": "cdi.kubevirt.io/v1beta1", "kind": "DataVolume",
+				"spec": {"storage": {"resources": {"requests": {"storage": "20Gi"}}}}}`
+			virtualMachine = `
+apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+spec:
+  template:
+    spec:
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - topologyKey: kubernetes.io/hostname
+      domain:
+        resources:
+          requests:
+            memory: 8Gi
+`
+		)
+
+		BeforeEach(func() {
+			tp.pipelines[0].Spec.Params = []pipeline.ParamSpec{{
+				Name:    "sourceTemplateName",
+				Default: pipeline.NewArrayOrString("windows10-desktop-large"),
+			}, {
+				Name:    "installerTemplateName",
+				Default: pipeline.NewArrayOrString("windows10-desktop-large-installer"),
+			}, {
+				Name:    "sourceTemplateNamespace",
+				Default: pipeline.NewArrayOrString("windows10-desktop-large"),
+			}}
+			tp.pipelines[0].Spec.Tasks = []pipeline.PipelineTask{{
+				Name: "modify-vm-template",
+				Params: []pipeline.Param{{
+					Name:  "datavolumeTemplates",
+					Value: *pipeline.NewArrayOrString(largeDataVolume, smallDataVolume),
+				}, {
+					Name:  "templateAnnotations",
+					Value: *pipeline.NewArrayOrString("openshift.io/display-name: Microsoft Windows 10"),
+				}},
+			}, {
+				Name: "create-vm-from-manifest",
+				Params: []pipeline.Param{{
+					Name:  "manifest",
+					Value: *pipeline.NewArrayOrString(virtualMachine),
+				}},
+			}}
+		})
+
+		It("should not adapt pipelines on highly available clusters", func() {
+			mockedRequest.TopologyMode = osconfv1.HighlyAvailableTopologyMode
+			Expect(adaptToTopology(mockedRequest, &tp.pipelines[0])).To(BeIdenticalTo(&tp.pipelines[0]))
+		})
+
+		It("should use medium templates on single node clusters", func() {
+			mockedRequest.TopologyMode = osconfv1.SingleReplicaTopologyMode
+			adapted := adaptToTopology(mockedRequest, &tp.pipelines[0])
+
+			Expect(adapted.Spec.Params[0].Default.StringVal).To(Equal("windows10-desktop-medium"))
+			Expect(adapted.Spec.Params[1].Default.StringVal).To(Equal("windows10-desktop-medium-installer"))
+			Expect(adapted.Spec.Params[2].Default.StringVal).To(Equal("windows10-desktop-large"), "only template names should be adapted")
+			Expect(tp.pipelines[0].Spec.Params[0].Default.StringVal).To(Equal("windows10-desktop-large"), "bundle should not be modified")
+		})
+
+		It("should limit storage requests of DataVolumes on single node clusters", func() {
+			mockedRequest.TopologyMode = osconfv1.SingleReplicaTopologyMode
+			adapted := adaptToTopology(mockedRequest, &tp.pipelines[0])
+
+			dataVolumes := adapted.Spec.Tasks[0].Params[0].Value.ArrayVal
+			Expect(dataVolumes).To(HaveLen(2))
+			storagePath := []string{"spec", "storage", "resources", "requests", "storage"}
+			Expect(manifestField(dataVolumes[0], storagePath...)).To(Equal("30Gi"))
+			Expect(manifestField(dataVolumes[1], storagePath...)).To(Equal("20Gi"))
+			Expect(manifestField(dataVolumes[0], "kind")).To(Equal("DataVolume"))
+
+			Expect(adapted.Spec.Tasks[0].Params[1].Value).To(Equal(tp.pipelines[0].Spec.Tasks[0].Params[1].Value),
+				"values, that are not manifests, should not be modified")
+			Expect(manifestField(tp.pipelines[0].Spec.Tasks[0].Params[0].Value.ArrayVal[0], storagePath...)).To(Equal("60Gi"),
+				"bundle should not be modified")
+		})
+
+		It("should remove pod anti-affinity of VMs on single node clusters", func() {
+			mockedRequest.TopologyMode = osconfv1.SingleReplicaTopologyMode
+			adapted := adaptToTopology(mockedRequest, &tp.pipelines[0])
+
+			vm := adapted.Spec.Tasks[1].Params[0].Value.StringVal
+			Expect(manifestField(vm, "spec", "template", "spec", "affinity")).To(BeEmpty())
+			Expect(manifestField(vm, "spec", "template", "spec", "domain", "resources", "requests", "memory")).To(Equal("8Gi"))
+		})
+	})
+
+	It("Dependencies function should return tekton-tasks operand", func() {
+		Expect(tp.Dependencies()).To(ConsistOf(tektontasks.OperandName), "pipelines should depend on tasks")
+	})
+
+	It("ReadyConditionType function should return tekton pipelines condition", func() {
+		Expect(string(tp.ReadyConditionType())).To(Equal(tekton.ConditionTektonPipelinesReady))
+	})
+
 	It("RequiredCrds function should return required crds", func() {
 		tp := getMockedTektonPipelinesOperand()
 		crds := tp.RequiredCrds()
@@ -176,3 +357,12 @@ func getMockedTestBundle() *tektonbundle.Bundle {
 		},
 	}
 }
+
+// manifestField returns the field of a JSON or YAML manifest passed to a task as a param.
+func manifestField(manifest string, fields ...string) interface{} {
+	obj := map[string]interface{}{}
+	ExpectWithOffset(1, yaml.Unmarshal([]byte(manifest), &obj)).To(Succeed())
+	value, _, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	return value
+}
@@ -0,0 +1,124 @@
+package tekton_pipelines
+
+import (
+	"encoding/json"
+	"strings"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+var (
+	// singleReplicaTemplates maps templates used by the example pipelines to templates, that request less memory for VMs.
+	singleReplicaTemplates = map[string]string{
+		"windows10-desktop-large": "windows10-desktop-medium",
+	}
+
+	// singleReplicaMaxStorage limits storage requested by DataVolumes in the example pipelines on single node clusters.
+	// The installation of Windows 10 needs at least 20Gi, so smaller requests are kept.
+	singleReplicaMaxStorage = resource.MustParse("30Gi")
+
+	// Paths of storage requests in DataVolumes
+	storageRequestPaths = [][]string{
+		{"spec", "storage", "resources", "requests", "storage"},
+		{"spec", "pvc", "resources", "requests", "storage"},
+	}
+
+	// Paths of pod anti-affinity in VirtualMachines and VirtualMachineInstances.
+	// All VMs run on the same node, so they cannot avoid each other.
+	podAntiAffinityPaths = [][]string{
+		{"spec", "template", "spec", "affinity", "podAntiAffinity"},
+		{"spec", "affinity", "podAntiAffinity"},
+	}
+)
+
+// adaptToTopology returns the pipeline adapted to the topology of the cluster.
+// The pipeline from the bundle is not modified, so it can be adapted again when the topology changes.
+func adaptToTopology(request *common.Request, p *pipeline.Pipeline) *pipeline.Pipeline {
+	if !common.IsSingleReplica(request) {
+		return p
+	}
+
+	adapted := p.DeepCopy()
+	for i := range adapted.Spec.Params {
+		param := &adapted.Spec.Params[i]
+		if param.Default != nil && strings.HasSuffix(param.Name, "TemplateName") {
+			param.Default.StringVal = singleReplicaTemplateName(param.Default.StringVal)
+		}
+	}
+	for _, tasks := range [][]pipeline.PipelineTask{adapted.Spec.Tasks, adapted.Spec.Finally} {
+		for i := range tasks {
+			for j := range tasks[i].Params {
+				value := &tasks[i].Params[j].Value
+				value.StringVal = adaptManifest(value.StringVal)
+				for k := range value.ArrayVal {
+					value.ArrayVal[k] = adaptManifest(value.ArrayVal[k])
+				}
+			}
+		}
+	}
+	return adapted
+}
+
+// singleReplicaTemplateName returns the name of the smaller template. Names of templates created
+// from the template, e.g. windows10-desktop-large-installer, are renamed as well.
+func singleReplicaTemplateName(name string) string {
+	for large, medium := range singleReplicaTemplates {
+		if name == large || strings.HasPrefix(name, large+"-") {
+			return medium + strings.TrimPrefix(name, large)
+		}
+	}
+	return name
+}
+
+// adaptManifest reduces storage requests and removes pod anti-affinity of a JSON or YAML manifest passed
+// to a task as a param. Other values and manifests, that do not need changes, are returned unchanged.
+func adaptManifest(value string) string {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(value), &obj); err != nil || len(obj) == 0 {
+		return value
+	}
+
+	changed := false
+	for _, path := range storageRequestPaths {
+		changed = limitStorageRequest(obj, path) || changed
+	}
+	for _, path := range podAntiAffinityPaths {
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj, path...); found {
+			unstructured.RemoveNestedField(obj, path...)
+			changed = true
+		}
+	}
+	if !changed {
+		return value
+	}
+
+	var adapted []byte
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		adapted, err = json.Marshal(obj)
+	} else {
+		adapted, err = yaml.Marshal(obj)
+	}
+	if err != nil {
+		return value
+	}
+	return string(adapted)
+}
+
+// limitStorageRequest sets the storage request at the path to singleReplicaMaxStorage, if it is larger.
+func limitStorageRequest(obj map[string]interface{}, path []string) bool {
+	value, found, err := unstructured.NestedString(obj, path...)
+	if err != nil || !found {
+		return false
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil || quantity.Cmp(singleReplicaMaxStorage) <= 0 {
+		return false
+	}
+	return unstructured.SetNestedField(obj, singleReplicaMaxStorage.String(), path...) == nil
+}
@@ -1,5 +1,7 @@
 package tekton_tasks
 
 const (
+	OperandName = "tekton-tasks"
+
 	TektonTasksVersionLabel = "tekton-tasks.kubevirt.io/version"
 )
@@ -0,0 +1,55 @@
+package tekton_tasks
+
+import (
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+)
+
+// runsUsingClusterTask returns running TaskRuns of the ClusterTask, and running PipelineRuns,
+// whose pipelines reference the ClusterTask, so they may still create its TaskRuns.
+// Running PipelineRuns are listed once, and shared by all ClusterTasks reconciled by the request.
+func runsUsingClusterTask(request *common.Request, found client.Object) ([]tekton.ResourceReference, error) {
+	var runs []tekton.ResourceReference
+
+	taskRuns := &pipeline.TaskRunList{}
+	err := request.Client.List(request.Context, taskRuns, client.MatchingLabels{tektonpipeline.ClusterTaskLabelKey: found.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		if !taskRun.IsDone() {
+			runs = append(runs, tekton.ResourceReference{Kind: taskRun.GetGroupVersionKind().Kind, Namespace: taskRun.Namespace, Name: taskRun.Name})
+		}
+	}
+
+	pipelineRuns, err := common.RunningPipelineRuns(request)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pipelineRuns {
+		pipelineRun := &pipelineRuns[i]
+		if referencesClusterTask(pipelineRun.Status.PipelineSpec, found.GetName()) {
+			runs = append(runs, tekton.ResourceReference{Kind: pipelineRun.GetGroupVersionKind().Kind, Namespace: pipelineRun.Namespace, Name: pipelineRun.Name})
+		}
+	}
+	return runs, nil
+}
+
+func referencesClusterTask(spec *pipeline.PipelineSpec, taskName string) bool {
+	if spec == nil {
+		return false
+	}
+	for _, tasks := range [][]pipeline.PipelineTask{spec.Tasks, spec.Finally} {
+		for _, task := range tasks {
+			if task.TaskRef != nil && task.TaskRef.Kind == pipeline.ClusterTaskKind && task.TaskRef.Name == taskName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,13 +1,15 @@
 package tekton_tasks
 
 import (
-	"fmt"
 	"strings"
 
+	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/migrations"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/operands"
 	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
@@ -30,9 +32,14 @@ import (
 // +kubebuilder:rbac:groups=*,resources=secrets,verbs=*
 
 const (
-	operandName      = "tekton-tasks"
+	operandName      = OperandName
 	operandComponent = common.AppComponentTektonTasks
 
+	clusterTasksGroup    = "clusterTasks"
+	clusterRolesGroup    = "clusterRoles"
+	serviceAccountsGroup = "serviceAccounts"
+	roleBindingsGroup    = "roleBindings"
+
 	cleanVMTaskName              = "cleanup-vm"
 	copyTemplateTaskName         = "copy-template"
 	modifyDataObjectTaskName     = "modify-data-object"
@@ -62,6 +69,24 @@ var AllowedTasks = map[string]func() string{
 	executeInVMTaskName:          environment.GetCleanupVMImage,
 }
 
+// taskAPIGroups lists API groups, that tasks use besides the core and Tekton APIs.
+// Tasks are deployed only when all their API groups are available.
+var taskAPIGroups = map[string][]string{
+	cleanVMTaskName:              {common.KubeVirtAPIGroup},
+	copyTemplateTaskName:         {common.TemplateAPIGroup},
+	modifyDataObjectTaskName:     {common.CDIAPIGroup},
+	createVMFromTemplateTaskName: {common.TemplateAPIGroup, common.KubeVirtAPIGroup},
+	modifyTemplateTaskName:       {common.TemplateAPIGroup},
+	waitForVMITaskName:           {common.KubeVirtAPIGroup},
+	createVMFromManifestTaskName: {common.KubeVirtAPIGroup},
+	executeInVMTaskName:          {common.KubeVirtAPIGroup},
+}
+
+// TaskAPIGroups returns API groups, that the task depends on.
+func TaskAPIGroups(taskName string) []string {
+	return taskAPIGroups[taskName]
+}
+
 func init() {
 	utilruntime.Must(pipeline.AddToScheme(common.Scheme))
 }
@@ -83,15 +108,24 @@ func New(bundle *tektonbundle.Bundle) *tektonTasks {
 		clusterRoles:    bundle.ClusterRoles,
 	}
 
-	tt.filterUnusedObjects()
-
-	return tt
+	return tt.filterTasks(func(taskName string) bool {
+		_, ok := AllowedTasks[taskName]
+		return ok
+	})
 }
 
 func (t *tektonTasks) Name() string {
 	return operandName
 }
 
+func (t *tektonTasks) Dependencies() []string {
+	return nil
+}
+
+func (t *tektonTasks) ReadyConditionType() conditionsv1.ConditionType {
+	return tekton.ConditionTektonTasksReady
+}
+
 func (t *tektonTasks) WatchClusterTypes() []client.Object {
 	return []client.Object{
 		&rbac.ClusterRole{},
@@ -109,116 +143,194 @@ func (t *tektonTasks) RequiredCrds() []string {
 	return requiredCRDs
 }
 
-func (t *tektonTasks) filterUnusedObjects() {
-	newTasks := []pipeline.ClusterTask{}
+func (t *tektonTasks) OptionalAPIGroups() []string {
+	var groups []string
 	for _, task := range t.clusterTasks {
-		if _, ok := AllowedTasks[task.Name]; ok {
-			newTasks = append(newTasks, task)
-		}
+		groups = append(groups, TaskAPIGroups(task.Name)...)
 	}
-	t.clusterTasks = newTasks
+	return groups
+}
 
-	newSA := []v1.ServiceAccount{}
+// filterTasks returns ClusterTasks and their ServiceAccounts, RoleBindings and ClusterRoles
+// of tasks, for which keep returns true.
+func (t *tektonTasks) filterTasks(keep func(taskName string) bool) *tektonTasks {
+	filtered := &tektonTasks{}
+	for _, task := range t.clusterTasks {
+		if keep(task.Name) {
+			filtered.clusterTasks = append(filtered.clusterTasks, task)
+		}
+	}
 	for _, sa := range t.serviceAccounts {
-		if _, ok := AllowedTasks[strings.TrimSuffix(sa.Name, "-task")]; ok {
-			newSA = append(newSA, sa)
+		if keep(strings.TrimSuffix(sa.Name, "-task")) {
+			filtered.serviceAccounts = append(filtered.serviceAccounts, sa)
 		}
 	}
-	t.serviceAccounts = newSA
-
-	newRB := []rbac.RoleBinding{}
 	for _, rb := range t.roleBindings {
-		if _, ok := AllowedTasks[strings.TrimSuffix(rb.Name, "-task")]; ok {
-			newRB = append(newRB, rb)
+		if keep(strings.TrimSuffix(rb.Name, "-task")) {
+			filtered.roleBindings = append(filtered.roleBindings, rb)
 		}
 	}
-	t.roleBindings = newRB
-
-	newCR := []rbac.ClusterRole{}
 	for _, cr := range t.clusterRoles {
-		if _, ok := AllowedTasks[strings.TrimSuffix(cr.Name, "-task")]; ok {
-			newCR = append(newCR, cr)
+		if keep(strings.TrimSuffix(cr.Name, "-task")) {
+			filtered.clusterRoles = append(filtered.clusterRoles, cr)
 		}
 	}
-	t.clusterRoles = newCR
+	return filtered
 }
 
 func (t *tektonTasks) Reconcile(request *common.Request) ([]common.ReconcileResult, error) {
 	var results []common.ReconcileResult
-	var reconcileFunc []common.ReconcileFunc
-	reconcileFunc = append(reconcileFunc, reconcileTektonTasksFuncs(t.clusterTasks)...)
-	reconcileFunc = append(reconcileFunc, reconcileClusterRoleFuncs(t.clusterRoles)...)
-	reconcileFunc = append(reconcileFunc, reconcileServiceAccountsFuncs(t.serviceAccounts)...)
-	reconcileFunc = append(reconcileFunc, reconcileRoleBindingFuncs(t.roleBindings)...)
+	missingGroups := make(map[string][]string)
+	for i := range t.clusterTasks {
+		task := &t.clusterTasks[i]
+		if missing := request.APIGroups.Missing(TaskAPIGroups(task.Name)); len(missing) > 0 {
+			missingGroups[task.Name] = missing
+			results = append(results, common.MissingAPIGroupsResult(task, missing))
+		}
+	}
+	isSkipped := func(taskName string) bool {
+		_, missing := missingGroups[taskName]
+		return missing
+	}
 
-	reconcileTektonBundleResults, err := common.CollectResourceStatus(request, reconcileFunc...)
+	// Tasks with missing API groups are left untouched, so tasks deployed before the API groups were removed are kept
+	groups := t.resourceGroups(func(taskName string) bool { return !isSkipped(taskName) })
+	reconcileTektonBundleResults, err := common.ReconcileGroups(request, groups...)
 	if err != nil {
 		return nil, err
 	}
 
-	upgradingNow := isUpgradingNow(request)
+	upgradingNow := migrations.IsUpgrading(request)
 	for _, r := range reconcileTektonBundleResults {
-		if !upgradingNow && (r.OperationResult == common.OperationResultUpdated) {
-			request.Logger.Info(fmt.Sprintf("Changes reverted in tekton tasks: %s", r.Resource.GetName()))
+		if !upgradingNow && (r.OperationResult == common.OperationResultUpdated) && !r.DesiredStateChanged() {
+			common.ReportRevertedChanges(request, r)
+		}
+	}
+	results = append(results, reconcileTektonBundleResults...)
+
+	if upgradingNow || request.BundleVariantChanged {
+		// Resources removed from the bundle by the upgrade, or missing in the selected variant, are deleted.
+		// Resources of skipped tasks are kept
+		desired := append(common.GroupResources(groups), t.filterTasks(isSkipped).resources(request.Instance.Namespace)...)
+		pruneResults, err := common.PruneResources(request, operandComponent, t.WatchClusterTypes(), desired)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, pruneResults...)
 	}
-	return append(results, reconcileTektonBundleResults...), nil
+	return results, nil
 }
 
 func (t *tektonTasks) Cleanup(request *common.Request) ([]common.CleanupResult, error) {
-	var objects []client.Object
-	for _, ct := range t.clusterTasks {
-		o := ct.DeepCopy()
-		objects = append(objects, o)
+	return common.CleanupGroups(request, t.resourceGroups(func(string) bool { return true })...)
+}
+
+// resources returns all resources of the tasks, namespaced resources are placed into the namespace.
+func (t *tektonTasks) resources(namespace string) []client.Object {
+	var resources []client.Object
+	for i := range t.clusterTasks {
+		resources = append(resources, &t.clusterTasks[i])
 	}
-	for _, cr := range t.clusterRoles {
-		o := cr.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.clusterRoles {
+		resources = append(resources, &t.clusterRoles[i])
 	}
-	for _, rb := range t.roleBindings {
-		o := rb.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.serviceAccounts {
+		t.serviceAccounts[i].Namespace = namespace
+		resources = append(resources, &t.serviceAccounts[i])
 	}
-	for _, sa := range t.serviceAccounts {
-		o := sa.DeepCopy()
-		objects = append(objects, o)
+	for i := range t.roleBindings {
+		t.roleBindings[i].Namespace = namespace
+		resources = append(resources, &t.roleBindings[i])
 	}
-	return common.DeleteAll(request, objects...)
+	return resources
 }
 
-func isUpgradingNow(request *common.Request) bool {
-	return request.Instance.Status.ObservedVersion != environment.GetOperatorVersion()
+// resourceGroups returns groups with resources of tasks, for which deployed returns true.
+func (t *tektonTasks) resourceGroups(deployed func(taskName string) bool) []common.ResourceGroup {
+	var clusterTasks []*pipeline.ClusterTask
+	var clusterRoles []*rbac.ClusterRole
+	var serviceAccounts []*v1.ServiceAccount
+	var roleBindings []*rbac.RoleBinding
+	var clusterTaskObjs, clusterRoleObjs, serviceAccountObjs, roleBindingObjs []client.Object
+	for i := range t.clusterTasks {
+		if deployed(t.clusterTasks[i].Name) {
+			clusterTasks = append(clusterTasks, &t.clusterTasks[i])
+			clusterTaskObjs = append(clusterTaskObjs, &t.clusterTasks[i])
+		}
+	}
+	for i := range t.clusterRoles {
+		if deployed(strings.TrimSuffix(t.clusterRoles[i].Name, "-task")) {
+			clusterRoles = append(clusterRoles, &t.clusterRoles[i])
+			clusterRoleObjs = append(clusterRoleObjs, &t.clusterRoles[i])
+		}
+	}
+	for i := range t.serviceAccounts {
+		if deployed(strings.TrimSuffix(t.serviceAccounts[i].Name, "-task")) {
+			serviceAccounts = append(serviceAccounts, &t.serviceAccounts[i])
+			serviceAccountObjs = append(serviceAccountObjs, &t.serviceAccounts[i])
+		}
+	}
+	for i := range t.roleBindings {
+		if deployed(strings.TrimSuffix(t.roleBindings[i].Name, "-task")) {
+			roleBindings = append(roleBindings, &t.roleBindings[i])
+			roleBindingObjs = append(roleBindingObjs, &t.roleBindings[i])
+		}
+	}
+
+	return []common.ResourceGroup{{
+		Name:      clusterTasksGroup,
+		Resources: clusterTaskObjs,
+		Funcs:     reconcileTektonTasksFuncs(clusterTasks),
+	}, {
+		Name:      clusterRolesGroup,
+		Resources: clusterRoleObjs,
+		Funcs:     reconcileClusterRoleFuncs(clusterRoles),
+	}, {
+		Name:      serviceAccountsGroup,
+		Resources: serviceAccountObjs,
+		Funcs:     reconcileServiceAccountsFuncs(serviceAccounts),
+	}, {
+		Name:      roleBindingsGroup,
+		DependsOn: []string{clusterRolesGroup, serviceAccountsGroup},
+		Resources: roleBindingObjs,
+		Funcs:     reconcileRoleBindingFuncs(roleBindings),
+	}}
 }
 
-func reconcileTektonTasksFuncs(tasks []pipeline.ClusterTask) []common.ReconcileFunc {
+func reconcileTektonTasksFuncs(tasks []*pipeline.ClusterTask) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(tasks))
 	for i := range tasks {
-		task := &tasks[i]
+		task := tasks[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			task.Spec.Steps[0].Image = AllowedTasks[task.Name]()
 			task.Labels[TektonTasksVersionLabel] = operands.TektonTasksVersion
 			return common.CreateOrUpdate(request).
 				ClusterResource(task).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newTask := newRes.(*pipeline.ClusterTask)
 					foundTask := foundRes.(*pipeline.ClusterTask)
 					foundTask.Spec = newTask.Spec
 				}).
+				DeferSpecUpdate(func(resource client.Object) interface{} {
+					return resource.(*pipeline.ClusterTask).Spec
+				}, runsUsingClusterTask).
 				Reconcile()
 		})
 	}
 	return funcs
 }
 
-func reconcileClusterRoleFuncs(crs []rbac.ClusterRole) []common.ReconcileFunc {
+func reconcileClusterRoleFuncs(crs []*rbac.ClusterRole) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(crs))
 	for i := range crs {
-		cr := &crs[i]
+		cr := crs[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			return common.CreateOrUpdate(request).
 				ClusterResource(cr).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newTask := newRes.(*rbac.ClusterRole)
 					foundTask := foundRes.(*rbac.ClusterRole)
@@ -230,38 +342,42 @@ func reconcileClusterRoleFuncs(crs []rbac.ClusterRole) []common.ReconcileFunc {
 	return funcs
 }
 
-func reconcileServiceAccountsFuncs(sas []v1.ServiceAccount) []common.ReconcileFunc {
+func reconcileServiceAccountsFuncs(sas []*v1.ServiceAccount) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(sas))
 	for i := range sas {
-		sa := &sas[i]
+		sa := sas[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			namespace := request.Instance.Namespace
 			sa.Namespace = namespace
 			return common.CreateOrUpdate(request).
 				ClusterResource(sa).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
+				StatusFunc(common.ServiceAccountStatus()).
 				Reconcile()
 		})
 	}
 	return funcs
 }
 
-func reconcileRoleBindingFuncs(rbs []rbac.RoleBinding) []common.ReconcileFunc {
+func reconcileRoleBindingFuncs(rbs []*rbac.RoleBinding) []common.ReconcileFunc {
 	funcs := make([]common.ReconcileFunc, 0, len(rbs))
 	for i := range rbs {
-		rb := &rbs[i]
+		rb := rbs[i]
 		funcs = append(funcs, func(request *common.Request) (common.ReconcileResult, error) {
 			namespace := request.Instance.Namespace
 			rb.Namespace = namespace
 			return common.CreateOrUpdate(request).
 				ClusterResource(rb).
 				WithAppLabels(operandName, operandComponent).
+				UpdateInWindows().
 				UpdateFunc(func(newRes, foundRes client.Object) {
 					newTask := newRes.(*rbac.RoleBinding)
 					foundTask := foundRes.(*rbac.RoleBinding)
 					foundTask.RoleRef = newTask.RoleRef
 					foundTask.Subjects = newTask.Subjects
 				}).
+				StatusFunc(common.RoleBindingStatus(request)).
 				Reconcile()
 		})
 	}
@@ -7,12 +7,15 @@ import (
 
 	tekton "github.com/kubevirt/tekton-tasks-operator/api/v1alpha1"
 	"github.com/kubevirt/tekton-tasks-operator/pkg/common"
+	"github.com/kubevirt/tekton-tasks-operator/pkg/environment"
 	tektonbundle "github.com/kubevirt/tekton-tasks-operator/pkg/tekton-bundle"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	tektonpipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -67,12 +70,143 @@ var _ = Describe("environments", func() {
 		Expect(name).To(Equal(operandName), "should return correct name")
 	})
 
+	It("ReadyConditionType function should return tekton tasks condition", func() {
+		Expect(string(tt.ReadyConditionType())).To(Equal(tekton.ConditionTektonTasksReady))
+	})
+
 	It("Reconcile function should return correct functions", func() {
 		functions, err := tt.Reconcile(mockedRequest)
 		Expect(err).ToNot(HaveOccurred(), "should not throw err")
 		Expect(len(functions)).To(Equal(8), "should return correct number of reconcile functions")
 	})
 
+	It("Reconcile function should skip tasks with missing API groups", func() {
+		mockedRequest.APIGroups = common.APIGroups{}
+		results, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+		Expect(results).To(HaveLen(5), "should not reconcile resources of skipped task")
+
+		var skipped []common.ReconcileResult
+		for _, result := range results {
+			if result.OperationResult == common.OperationResultMissingAPIGroups {
+				skipped = append(skipped, result)
+			}
+		}
+		Expect(skipped).To(HaveLen(1))
+		Expect(skipped[0].Resource.GetName()).To(Equal(modifyTemplateTaskName))
+		Expect(skipped[0].MissingAPIGroups).To(ConsistOf(common.TemplateAPIGroup))
+		Expect(skipped[0].IsSuccess()).To(BeTrue(), "skipped task should not be a failure")
+
+		err = mockedRequest.Client.Get(mockedRequest.Context, types.NamespacedName{Name: modifyTemplateTaskName}, &pipeline.ClusterTask{})
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "skipped task should not be created")
+	})
+
+	It("Reconcile function should deploy tasks when API groups are available", func() {
+		mockedRequest.APIGroups = common.APIGroups{common.TemplateAPIGroup: true}
+		results, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+		Expect(results).To(HaveLen(8))
+		for _, result := range results {
+			Expect(result.OperationResult).ToNot(Equal(common.OperationResultMissingAPIGroups))
+		}
+	})
+
+	It("Reconcile function should prune tasks missing in the selected bundle variant", func() {
+		// Not upgrading, so resources are pruned only because of the changed variant
+		mockedRequest.Instance.Status.ObservedVersion = environment.GetOperatorVersion()
+		_, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		kubernetesVariant := tt.filterTasks(func(taskName string) bool { return taskName != modifyTemplateTaskName })
+		mockedRequest.BundleVariantChanged = true
+		_, err = kubernetesVariant.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		err = mockedRequest.Client.Get(mockedRequest.Context, types.NamespacedName{Name: modifyTemplateTaskName}, &pipeline.ClusterTask{})
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "task missing in the selected variant should be deleted")
+		err = mockedRequest.Client.Get(mockedRequest.Context, types.NamespacedName{Name: diskVirtSysprepTaskName}, &pipeline.ClusterTask{})
+		Expect(err).ToNot(HaveOccurred(), "task of the selected variant should be kept")
+	})
+
+	It("Reconcile function should defer updates of tasks used by running runs", func() {
+		_, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		taskRun := &pipeline.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-taskrun",
+				Namespace: namespace,
+				Labels:    map[string]string{tektonpipeline.ClusterTaskLabelKey: diskVirtSysprepTaskName},
+			},
+		}
+		Expect(mockedRequest.Client.Create(mockedRequest.Context, taskRun)).To(Succeed())
+		pipelineRun := &pipeline.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pipelinerun",
+				Namespace: namespace,
+				Labels:    map[string]string{tektonpipeline.PipelineLabelKey: "test-pipeline"},
+			},
+			Status: pipeline.PipelineRunStatus{
+				PipelineRunStatusFields: pipeline.PipelineRunStatusFields{
+					PipelineSpec: &pipeline.PipelineSpec{
+						Tasks: []pipeline.PipelineTask{{
+							Name:    "modify",
+							TaskRef: &pipeline.TaskRef{Name: modifyTemplateTaskName, Kind: pipeline.ClusterTaskKind},
+						}},
+					},
+				},
+			},
+		}
+		Expect(mockedRequest.Client.Create(mockedRequest.Context, pipelineRun)).To(Succeed())
+
+		mockedRequest.Instance.Spec.UpdateStrategy.WaitForRuns = true
+		for i := range tt.clusterTasks {
+			tt.clusterTasks[i].Spec.Steps[0].Name = "updated"
+		}
+		results, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		deferred := map[string][]tekton.ResourceReference{}
+		for _, result := range results {
+			if result.OperationResult == common.OperationResultDeferred {
+				deferred[result.Resource.GetName()] = result.Runs
+			}
+		}
+		Expect(deferred).To(HaveLen(2))
+		Expect(deferred[diskVirtSysprepTaskName]).To(ConsistOf(tekton.ResourceReference{Kind: "TaskRun", Namespace: namespace, Name: taskRun.Name}))
+		Expect(deferred[modifyTemplateTaskName]).To(ConsistOf(tekton.ResourceReference{Kind: "PipelineRun", Namespace: namespace, Name: pipelineRun.Name}))
+
+		found := &pipeline.ClusterTask{}
+		Expect(mockedRequest.Client.Get(mockedRequest.Context, types.NamespacedName{Name: diskVirtSysprepTaskName}, found)).To(Succeed())
+		Expect(found.Spec.Steps[0].Name).To(Equal("test"), "task used by a running run should not be updated")
+	})
+
+	It("Reconcile function should only create missing resources outside update windows", func() {
+		deployed := tt.filterTasks(func(taskName string) bool { return taskName == diskVirtSysprepTaskName })
+		_, err := deployed.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		mockedRequest.UpdateWindowClosed = true
+		tt.clusterTasks[0].Spec.Steps[0].Name = "updated"
+		results, err := tt.Reconcile(mockedRequest)
+		Expect(err).ToNot(HaveOccurred(), "should not throw err")
+
+		operationResults := map[string]common.OperationResult{}
+		for _, result := range results {
+			if _, isTask := result.Resource.(*pipeline.ClusterTask); isTask {
+				operationResults[result.Resource.GetName()] = result.OperationResult
+			}
+		}
+		Expect(operationResults).To(Equal(map[string]common.OperationResult{
+			diskVirtSysprepTaskName: common.OperationResultDeferred,
+			modifyTemplateTaskName:  common.OperationResultCreated,
+		}))
+	})
+
+	It("OptionalAPIGroups function should return API groups of tasks", func() {
+		Expect(tt.OptionalAPIGroups()).To(ConsistOf(common.TemplateAPIGroup))
+	})
+
 	It("RequiredCrds function should return required crds", func() {
 		tt := getMockedTektonTasksOperand()
 		crds := tt.RequiredCrds()